@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/history"
+	"github.com/sleexyz/j/internal/justfile"
+	"github.com/sleexyz/j/internal/repo"
+)
+
+var againHere bool
+
+var againCmd = &cobra.Command{
+	Use:     "again [n]",
+	Aliases: []string{"!!"},
+	Short:   "Re-run a recent command",
+	Long: `Re-run the most recent successful run from ` + "`j history`" + `, or the nth most
+recent one, from anywhere. The recipes run in the same directory with the same
+arguments as the first time.
+
+With --here, only runs in the directory ` + "`j <target>`" + ` would use from the current
+directory count.
+
+Shells expand !! themselves, so quote it: j '!!'.`,
+	Example: `  j again                         # Re-run the last command
+  j again 3                       # Re-run the third most recent command
+  j again --here                  # Re-run the last command run in this directory`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAgain,
+}
+
+func init() {
+	againCmd.Flags().BoolVar(&againHere, "here", false, "only consider runs in the current directory's justfile")
+	againCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output")
+	againCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func runAgain(cmd *cobra.Command, args []string) error {
+	n := 1
+	if len(args) == 1 {
+		var err error
+		n, err = strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("expected a positive number of runs back, got %q", args[0])
+		}
+	}
+	cmd.SilenceUsage = true
+
+	entries, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	var here string
+	if againHere {
+		here, err = hereDir()
+		if err != nil {
+			return err
+		}
+	}
+
+	entry, err := recentRun(entries, n, here)
+	if err != nil {
+		return err
+	}
+
+	for _, call := range entry.Calls {
+		if err := justfile.ValidateTarget(entry.JustfilePath, call.Target); err != nil {
+			return err
+		}
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Running: %s\n", historyCommand(entry))
+	}
+	if err := justfile.RunCalls(entry.JustfilePath, entry.JustFlags, entry.Calls, verbose && !quiet); err != nil {
+		return err
+	}
+
+	recordRun(&resolvedTarget{
+		RepoRoot:     entry.RepoRoot,
+		JustfilePath: entry.JustfilePath,
+		WorkingDir:   entry.Dir(),
+	}, entry.JustFlags, entry.Calls)
+	return nil
+}
+
+// recentRun returns the nth most recent run in entries, counting only runs
+// in the directory here if it's set
+func recentRun(entries []history.Entry, n int, here string) (history.Entry, error) {
+	count := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		if here != "" && entries[i].Dir() != here {
+			continue
+		}
+		count++
+		if count == n {
+			return entries[i], nil
+		}
+	}
+
+	where := ""
+	if here != "" {
+		where = " in " + here
+	}
+	switch {
+	case count == 0:
+		return history.Entry{}, fmt.Errorf("no runs in history%s", where)
+	default:
+		return history.Entry{}, fmt.Errorf("only %d run(s) in history%s", count, where)
+	}
+}
+
+// hereDir returns the directory a bare `j <target>` runs in from the current
+// directory
+func hereDir() (string, error) {
+	var repoRoot string
+	root, err := detectRepoRoot()
+	if err == nil {
+		repoRoot = root.Path
+	} else if !errors.Is(err, repo.ErrNoRepoRoot) {
+		return "", err
+	}
+
+	justfilePath, err := justfile.FindBestJustfile(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(justfilePath), nil
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/config"
+	"github.com/sleexyz/j/internal/justfile"
+	"github.com/sleexyz/j/internal/repo"
+)
+
+var doctorFormat string
+
+// Check statuses reported by j doctor
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// checkResult is the outcome of a single doctor check
+type checkResult struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with your j setup",
+	Long: `Check that j's dependencies are installed and that the current repository
+is set up correctly: the just and git binaries, repo root detection, which
+justfile would be used from here, justfiles that fail to parse, recipe names
+defined in more than one justfile, shell completion, and config files.`,
+	Example: `  j doctor                        # Run all checks
+  j doctor --format json          # Output results as JSON`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+	// Failing checks are already explained in the output
+	SilenceUsage: true,
+}
+
+func init() {
+	doctorCmd.Flags().StringVarP(&doctorFormat, "format", "f", "table", "output format (table, json)")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	results := []checkResult{
+		checkJust(),
+		checkGit(),
+	}
+
+	root, err := repo.DetectRepoRoot()
+	results = append(results, checkRepoRoot(root, err))
+
+	var rootPath string
+	if root != nil {
+		rootPath = root.Path
+	}
+
+	results = append(results, checkBestJustfile(rootPath))
+	if root != nil {
+		files, err := justfile.ParseAll(cmd.Context(), rootPath)
+		if err != nil {
+			results = append(results, checkResult{Name: "parse", Status: checkFail, Message: err.Error()})
+		} else {
+			results = append(results, checkJustfiles(rootPath, files)...)
+			results = append(results, checkAliases(rootPath, files))
+		}
+	}
+
+	results = append(results, checkCompletion())
+	results = append(results, checkConfig(rootPath))
+
+	if err := outputChecks(results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func checkJust() checkResult {
+	path, err := exec.LookPath("just")
+	if err != nil {
+		return checkResult{
+			Name:    "just",
+			Status:  checkFail,
+			Message: "just not found on PATH",
+			Details: []string{"install it from https://github.com/casey/just"},
+		}
+	}
+	return checkResult{Name: "just", Status: checkPass, Message: binaryVersion(path) + " (" + path + ")"}
+}
+
+func checkGit() checkResult {
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return checkResult{
+			Name:    "git",
+			Status:  checkWarn,
+			Message: "git not found on PATH; repository root detection is limited",
+		}
+	}
+	return checkResult{Name: "git", Status: checkPass, Message: binaryVersion(path) + " (" + path + ")"}
+}
+
+// binaryVersion returns the first line of `<path> --version`
+func binaryVersion(path string) string {
+	output, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return "unknown version"
+	}
+	version, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return version
+}
+
+func checkRepoRoot(root *repo.Root, err error) checkResult {
+	if err != nil {
+		return checkResult{Name: "repo root", Status: checkFail, Message: err.Error()}
+	}
+
+	result := checkResult{
+		Name:    "repo root",
+		Status:  checkPass,
+		Message: fmt.Sprintf("%s (detected by %s)", root.Path, root.Detector),
+	}
+	if root.Inner != root.Path {
+		result.Details = []string{fmt.Sprintf("innermost repository is %s; use @@path or @/path to resolve against it", root.Inner)}
+	}
+	return result
+}
+
+func checkBestJustfile(repoRoot string) checkResult {
+	justfilePath, err := justfile.FindBestJustfile(repoRoot)
+	if err != nil {
+		return checkResult{
+			Name:    "justfile",
+			Status:  checkWarn,
+			Message: err.Error(),
+			Details: []string{"use @path to run targets from a subdirectory"},
+		}
+	}
+	return checkResult{Name: "justfile", Status: checkPass, Message: justfilePath}
+}
+
+// checkJustfiles reports parse problems in every justfile in the repo and
+// recipe names that are defined in more than one justfile
+func checkJustfiles(repoRoot string, files []justfile.FileTargets) []checkResult {
+	parse := checkResult{Name: "parse", Status: checkPass}
+	locations := make(map[string][]string)
+	targetCount := 0
+	for _, file := range files {
+		for _, d := range file.Diagnostics {
+			parse.Details = append(parse.Details, d.Error())
+		}
+		dir := displayPath(repoRoot, filepath.Dir(file.Path))
+		for _, target := range file.Targets {
+			// Same-file duplicates are already reported as parse problems
+			if dirs := locations[target.Name]; len(dirs) == 0 || dirs[len(dirs)-1] != dir {
+				locations[target.Name] = append(dirs, dir)
+			}
+			targetCount++
+		}
+	}
+	if len(parse.Details) > 0 {
+		parse.Status = checkFail
+		parse.Message = fmt.Sprintf("%d problem(s) in justfiles", len(parse.Details))
+	} else {
+		parse.Message = fmt.Sprintf("%d justfile(s), %d target(s)", len(files), targetCount)
+	}
+
+	duplicates := checkResult{Name: "duplicates", Status: checkPass, Message: "no recipe names defined in more than one justfile"}
+	var names []string
+	for name, dirs := range locations {
+		if len(dirs) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		duplicates.Details = append(duplicates.Details, fmt.Sprintf("%s: %s", name, strings.Join(locations[name], ", ")))
+	}
+	if len(names) > 0 {
+		duplicates.Status = checkWarn
+		duplicates.Message = fmt.Sprintf("%d recipe name(s) defined in more than one justfile; use @path to pick one", len(names))
+	}
+
+	return []checkResult{parse, duplicates}
+}
+
+// checkAliases reports aliases defined more than once with different
+// directories, aliases pointing at missing directories, and aliases hidden
+// by a real directory of the same name
+func checkAliases(repoRoot string, files []justfile.FileTargets) checkResult {
+	aliases := repo.AliasesFromFiles(repoRoot, files)
+	result := checkResult{Name: "aliases", Status: checkPass, Message: fmt.Sprintf("%d alias(es)", len(aliases))}
+	if len(aliases) == 0 {
+		result.Message = "no aliases defined"
+		return result
+	}
+
+	escalate := func(status string) {
+		if status == checkFail || result.Status == checkPass {
+			result.Status = status
+		}
+	}
+
+	first := make(map[string]repo.Alias)
+	for _, alias := range aliases {
+		if winner, ok := first[alias.Name]; ok {
+			if winner.Path != alias.Path {
+				escalate(checkFail)
+				result.Details = append(result.Details, fmt.Sprintf("@%s is defined as @%s in %s and as @%s in %s",
+					alias.Name, winner.Path, winner.Source, alias.Path, alias.Source))
+			}
+			continue
+		}
+		first[alias.Name] = alias
+
+		if info, err := os.Stat(filepath.Join(repoRoot, filepath.FromSlash(alias.Path))); err != nil || !info.IsDir() {
+			escalate(checkFail)
+			result.Details = append(result.Details, fmt.Sprintf("@%s points to @%s, which is not a directory (%s)", alias.Name, alias.Path, alias.Source))
+		}
+		if _, err := os.Stat(filepath.Join(repoRoot, alias.Name)); err == nil && alias.Path != alias.Name {
+			escalate(checkWarn)
+			result.Details = append(result.Details, fmt.Sprintf("@%s is hidden by the directory of the same name (%s)", alias.Name, alias.Source))
+		}
+	}
+
+	if result.Status != checkPass {
+		result.Message = fmt.Sprintf("%d problem(s) with aliases", len(result.Details))
+	}
+	return result
+}
+
+// displayPath renders dir as an @path relative to repoRoot
+func displayPath(repoRoot, dir string) string {
+	relPath, err := filepath.Rel(repoRoot, dir)
+	if err != nil || relPath == "." {
+		return "@ (repo root)"
+	}
+	return "@" + relPath
+}
+
+func checkCompletion() checkResult {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	home, _ := os.UserHomeDir()
+
+	var files, rcFiles []string
+	switch shell {
+	case "zsh":
+		var dirs []string
+		if fpath := os.Getenv("FPATH"); fpath != "" {
+			dirs = append(dirs, filepath.SplitList(fpath)...)
+		}
+		dirs = append(dirs,
+			filepath.Join(home, ".zsh", "completions"),
+			filepath.Join(home, ".zfunc"),
+			filepath.Join(home, ".nix-profile", "share", "zsh", "site-functions"),
+			"/etc/profiles/per-user/"+os.Getenv("USER")+"/share/zsh/site-functions",
+			"/run/current-system/sw/share/zsh/site-functions",
+			"/usr/local/share/zsh/site-functions",
+			"/opt/homebrew/share/zsh/site-functions",
+			"/usr/share/zsh/site-functions",
+			"/usr/share/zsh/vendor-completions",
+		)
+		for _, dir := range dirs {
+			files = append(files, filepath.Join(dir, "_j"), filepath.Join(dir, "_j_dynamic"))
+		}
+		rcFiles = []string{filepath.Join(home, ".zshrc")}
+	case "bash":
+		files = []string{
+			filepath.Join(dataHome(home), "bash-completion", "completions", "j"),
+			filepath.Join(home, ".nix-profile", "share", "bash-completion", "completions", "j"),
+			"/etc/bash_completion.d/j",
+			"/usr/local/etc/bash_completion.d/j",
+			"/opt/homebrew/etc/bash_completion.d/j",
+			"/usr/share/bash-completion/completions/j",
+		}
+		rcFiles = []string{filepath.Join(home, ".bashrc"), filepath.Join(home, ".bash_profile")}
+	case "fish":
+		files = []string{
+			filepath.Join(configHome(home), "fish", "completions", "j.fish"),
+			filepath.Join(home, ".nix-profile", "share", "fish", "vendor_completions.d", "j.fish"),
+			"/usr/share/fish/vendor_completions.d/j.fish",
+		}
+		rcFiles = []string{filepath.Join(configHome(home), "fish", "config.fish")}
+	default:
+		return checkResult{
+			Name:    "completion",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("can't check completion for shell %q", os.Getenv("SHELL")),
+		}
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			return checkResult{Name: "completion", Status: checkPass, Message: fmt.Sprintf("%s completion installed (%s)", shell, file)}
+		}
+	}
+	for _, rcFile := range rcFiles {
+		data, err := os.ReadFile(rcFile)
+		if err == nil && strings.Contains(string(data), "j completion "+shell) {
+			return checkResult{Name: "completion", Status: checkPass, Message: fmt.Sprintf("%s completion loaded from %s", shell, rcFile)}
+		}
+	}
+
+	return checkResult{
+		Name:    "completion",
+		Status:  checkWarn,
+		Message: fmt.Sprintf("no %s completion found for j", shell),
+		Details: []string{fmt.Sprintf("see `j completion --help` for how to install it for %s", shell)},
+	}
+}
+
+// configHome returns $XDG_CONFIG_HOME or its default
+func configHome(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(home, ".config")
+}
+
+// dataHome returns $XDG_DATA_HOME or its default
+func dataHome(home string) string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(home, ".local", "share")
+}
+
+func checkConfig(repoRoot string) checkResult {
+	result := checkResult{Name: "config", Status: checkPass}
+
+	var found []string
+	for _, path := range config.Paths(repoRoot) {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		found = append(found, path)
+		if _, err := config.LoadFile(path); err != nil {
+			result.Status = checkFail
+			result.Details = append(result.Details, err.Error())
+		}
+	}
+
+	switch {
+	case result.Status == checkFail:
+		result.Message = "invalid config file(s)"
+	case len(found) == 0:
+		result.Message = "no config files (using defaults)"
+	default:
+		result.Message = strings.Join(found, ", ")
+	}
+	return result
+}
+
+func outputChecks(results []checkResult) error {
+	switch doctorFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case "table":
+		for _, result := range results {
+			fmt.Printf("[%s] %s: %s\n", result.Status, result.Name, result.Message)
+			for _, detail := range result.Details {
+				fmt.Printf("       %s\n", detail)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format: %s", doctorFormat)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/picker"
+)
+
+// ansiPattern matches the color codes in `j list --format fzf` lines
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// runFzf lets the user choose a recipe with an external fzf, previewing
+// recipes with `j show`, then runs it like the built-in picker does
+func runFzf(cmd *cobra.Command) error {
+	cmd.SilenceUsage = true
+	fzf, err := exec.LookPath("fzf")
+	if err != nil {
+		return errors.New("fzf not found on PATH; run j pick without --fzf for the built-in picker")
+	}
+
+	repoRoot, items, err := pickerChoices(cmd)
+	if err != nil {
+		return err
+	}
+
+	// fzf keeps input order between equally good matches, so list what's
+	// run most often first
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Frecency > items[j].Frecency
+	})
+
+	var input bytes.Buffer
+	byName := make(map[string]picker.Item)
+	for _, item := range items {
+		line := fzfLine(repoRoot, TargetInfo{
+			Name:        item.Target.Name,
+			Description: item.Target.Description,
+			Directory:   filepath.Dir(item.Target.JustfilePath),
+		}, true)
+		name, _, _ := strings.Cut(ansiPattern.ReplaceAllString(line, ""), "\t")
+		if _, ok := byName[name]; ok {
+			continue
+		}
+		byName[name] = item
+		fmt.Fprintln(&input, line)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	fzfCmd := exec.Command(fzf,
+		"--ansi",
+		"--delimiter", "\t",
+		"--tiebreak", "index",
+		"--prompt", "j> ",
+		"--preview", shellQuote(self)+" show --color always {1}",
+	)
+	fzfCmd.Stdin = &input
+	fzfCmd.Stderr = os.Stderr
+	out, err := fzfCmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+		// Nothing matched, or the user quit
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fzf: %w", err)
+	}
+
+	selected := strings.TrimRight(ansiPattern.ReplaceAllString(string(out), ""), "\n")
+	name, _, _ := strings.Cut(selected, "\t")
+	item, ok := byName[name]
+	if !ok {
+		return fmt.Errorf("fzf returned an unknown recipe: %q", selected)
+	}
+	return runChosen(cmd.Context(), item.Target, item.Dir, repoRoot)
+}
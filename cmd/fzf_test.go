@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sleexyz/j/internal/testrepo"
+	"github.com/spf13/cobra"
+)
+
+// stubFzf stands in for fzf: it records its arguments and input next to
+// itself, then "selects" the first input line containing $FZF_PICK
+const stubFzf = `#!/bin/sh
+dir=$(dirname "$0")
+printf '%s\n' "$@" > "$dir/args"
+cat > "$dir/input"
+grep -F -m1 -- "$FZF_PICK" "$dir/input" || exit 1
+`
+
+// fzfRepo creates a repository with a documented build recipe in the root,
+// api and co:lon. Root detection is cached per directory, so it's made the
+// current directory too.
+func fzfRepo(t *testing.T) {
+	t.Helper()
+	files := make(map[string]string)
+	for _, dir := range []string{".", "api", "co:lon"} {
+		files[dir+"/justfile"] = "# Build " + dir + "\nbuild:\n  echo build\n"
+	}
+	testrepo.Chdir(t, testrepo.New(t, files))
+}
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+
+	err = fn()
+	os.Stdout = stdout
+	w.Close()
+	out := <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestListFzf(t *testing.T) {
+	fzfRepo(t)
+	outputFormat, recursive, listColor = "fzf", true, "never"
+	t.Cleanup(func() { outputFormat, recursive, listColor = "table", false, "auto" })
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	out := captureStdout(t, func() error { return listTargets(cmd, nil) })
+
+	for _, want := range []string{
+		"build\t@\tBuild .",
+		"api:build\t@api\tBuild api",
+		`co\:lon:build` + "\t@co:lon\tBuild co:lon",
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("j list --format fzf = %q, want a line %q", out, want)
+		}
+	}
+
+	listColor = "always"
+	out = captureStdout(t, func() error { return listTargets(cmd, nil) })
+	if !strings.Contains(out, "\x1b[") {
+		t.Errorf("j list --format fzf --color always = %q, want colors", out)
+	}
+}
+
+func TestPickFzf(t *testing.T) {
+	fzfRepo(t)
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "fzf"), []byte(stubFzf), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	printFlag = true
+	t.Cleanup(func() { printFlag = false })
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	for pick, want := range map[string]string{
+		"api:build":     "j build @api",
+		`co\:lon:build`: "j build @co:lon",
+	} {
+		t.Setenv("FZF_PICK", pick)
+		if got := captureStdout(t, func() error { return runFzf(cmd) }); got != want+"\n" {
+			t.Errorf("picking %s printed %q, want %q", pick, got, want)
+		}
+	}
+
+	args, err := os.ReadFile(filepath.Join(bin, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(args), "--ansi\n") || !strings.Contains(string(args), " show --color always {1}\n") {
+		t.Errorf("fzf was run with %q, want --ansi and a j show preview", args)
+	}
+
+	// Nothing matching is not an error
+	t.Setenv("FZF_PICK", "nope")
+	if got := captureStdout(t, func() error { return runFzf(cmd) }); got != "" {
+		t.Errorf("picking nothing printed %q", got)
+	}
+}
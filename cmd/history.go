@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/config"
+	"github.com/sleexyz/j/internal/fuzzy"
+	"github.com/sleexyz/j/internal/history"
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+var (
+	historyFormat string
+	historyLimit  int
+)
+
+// historyItem is one run in the output of `j history --format json`
+type historyItem struct {
+	history.Entry
+	Command string `json:"command"`
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history [query]",
+	Short: "Show past runs",
+	Long: `Show the recipes j has run successfully, most recent first. With a query,
+only runs whose command fuzzily matches it are shown.
+
+Runs are recorded in ` + "`~/.local/state/j/history.jsonl`" + ` (or $XDG_STATE_HOME/j, or
+$J_HISTORY) and rank shell completions, so what you run most often and most
+recently comes first. Each run is stored as typed, including recipe arguments
+and --set values, so secrets passed on the command line end up in the file.
+Set "disable_history": true in your config to turn this off.`,
+	Example: `  j history                       # Show the last 20 runs
+  j history dev                   # Show runs matching 'dev'
+  j history -n 0 --format json    # Output every run as JSON
+  j history clear                 # Forget every run
+  j history -- clear              # Show runs matching 'clear'`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: showHistory,
+}
+
+var historyClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the run history",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := history.Clear(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Cleared %s\n", history.Path())
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVarP(&historyFormat, "format", "f", "table", "output format (table, json)")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "number of runs to show (0 for all)")
+	historyCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	historyCmd.AddCommand(historyClearCmd)
+}
+
+func showHistory(cmd *cobra.Command, args []string) error {
+	entries, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	// Most recent first
+	var items []historyItem
+	for i := len(entries) - 1; i >= 0; i-- {
+		item := historyItem{Entry: entries[i], Command: historyCommand(entries[i])}
+		if len(args) == 1 && fuzzy.Score(args[0], item.Command) == 0 {
+			continue
+		}
+		items = append(items, item)
+		if historyLimit > 0 && len(items) == historyLimit {
+			break
+		}
+	}
+
+	switch historyFormat {
+	case "json":
+		if items == nil {
+			items = []historyItem{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "WHEN\tCOMMAND\tFROM")
+		now := time.Now()
+		for _, item := range items {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", ago(now, item.Time), item.Command, item.Cwd)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported output format: %s", historyFormat)
+	}
+}
+
+// recordRun adds a successful run to the history, unless history is turned
+// off. Failing to record it doesn't fail the run.
+func recordRun(resolved *resolvedTarget, justFlags []string, calls []justfile.Call) {
+	cfg, err := config.Load(resolved.RepoRoot)
+	if err != nil || cfg.DisableHistory {
+		return
+	}
+
+	cwd, _ := os.Getwd()
+	entry := history.Entry{
+		Time:         time.Now(),
+		Cwd:          cwd,
+		RepoRoot:     resolved.RepoRoot,
+		RepoPath:     canonicalRepoPath(resolved.RepoRoot, resolved.WorkingDir),
+		JustfilePath: resolved.JustfilePath,
+		JustFlags:    justFlags,
+		Calls:        calls,
+	}
+	if err := history.Record(entry); err != nil && verbose && !quiet {
+		fmt.Fprintf(os.Stderr, "warning: couldn't record run in history: %v\n", err)
+	}
+}
+
+// canonicalRepoPath returns the @path of dir relative to repoRoot, or "" if
+// there's no repository root. dir may be relative to the current directory,
+// as it is with -d.
+func canonicalRepoPath(repoRoot, dir string) string {
+	if repoRoot == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	relPath, err := filepath.Rel(repoRoot, dir)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return ""
+	}
+	if relPath == "." {
+		return "@"
+	}
+	return "@" + filepath.ToSlash(relPath)
+}
+
+// historyCommand formats the j command that repeats entry from anywhere in
+// its repository
+func historyCommand(entry history.Entry) string {
+	return commandLine(entry.JustFlags, entry.RepoPath, entry.Calls)
+}
+
+// ago formats how long before now t was, briefly
+func ago(now, t time.Time) string {
+	switch d := now.Sub(t); {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	default:
+		return t.Format("2006-01-02")
+	}
+}
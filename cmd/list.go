@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,14 +12,19 @@ import (
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/argv"
 	"github.com/sleexyz/j/internal/completion"
 	"github.com/sleexyz/j/internal/justfile"
 	"github.com/sleexyz/j/internal/repo"
+	"github.com/sleexyz/j/internal/ui"
 )
 
 var (
 	outputFormat string
 	recursive    bool
+	strict       bool
+	withErrors   bool
+	listColor    string
 )
 
 type TargetInfo struct {
@@ -24,6 +32,16 @@ type TargetInfo struct {
 	Description string `json:"description,omitempty"`
 	Directory   string `json:"directory"`
 	JustfilePath string `json:"justfile_path"`
+	Line         int    `json:"line,omitempty"`
+	// Aliases are the @name aliases for Directory (only set by --recursive)
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// listOutput is the JSON document printed by `j list --format json
+// --with-errors`
+type listOutput struct {
+	Targets []TargetInfo          `json:"targets"`
+	Errors  []justfile.Diagnostic `json:"errors"`
 }
 
 var listCmd = &cobra.Command{
@@ -36,15 +54,21 @@ With a @path argument, lists targets from that specific directory.`,
 	Example: `  j list                          # List targets in current directory or repo root
   j list @frontend               # List targets in frontend directory
   j list --format json           # Output as JSON
+  j list -r -f json --with-errors  # JSON with the problems found in justfiles
   j list --recursive             # List targets from all justfiles in repo
+  j list --recursive --strict    # Fail if any justfile has problems
+  j list -r -f fzf --color always | fzf --ansi  # Tab-separated, colored lines for fzf
   j -l                           # Short flag for list (just compatibility)`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: listTargets,
 }
 
 func init() {
-	listCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "output format (table, json)")
+	listCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "output format (table, json, fzf)")
 	listCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "include subdirectories")
+	listCmd.Flags().BoolVar(&strict, "strict", false, "fail if any justfile has problems")
+	listCmd.Flags().BoolVar(&withErrors, "with-errors", false, "with --format json, output {targets, errors} instead of an array of targets")
+	listCmd.Flags().StringVar(&listColor, "color", "auto", "with --format fzf, color output (auto, always, never)")
 	
 	// Set up completion for path argument
 	listCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -56,12 +80,17 @@ func init() {
 }
 
 func listTargets(cmd *cobra.Command, args []string) error {
-	repoRoot, err := repo.FindRepoRoot()
-	if err != nil {
+	// Listing the current directory's justfile works without a repo root
+	var repoRoot string
+	root, err := detectRepoRoot()
+	if err == nil {
+		repoRoot = root.Path
+	} else if !errors.Is(err, repo.ErrNoRepoRoot) || len(args) == 1 || recursive {
 		return fmt.Errorf("failed to find repository root: %w", err)
 	}
 
 	var targets []TargetInfo
+	var diagnostics []justfile.Diagnostic
 
 	if len(args) == 1 {
 		// List targets from specific path
@@ -70,18 +99,18 @@ func listTargets(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("path must start with @, got: %s", repoPath)
 		}
 
-		resolvedPath, err := repo.ResolveRepoPath(repoPath, repoRoot)
+		resolvedPath, err := resolveRepoPath(root, repoPath, false)
 		if err != nil {
-			return fmt.Errorf("failed to resolve path %s: %w", repoPath, err)
+			return err
 		}
 
-		targets, err = getTargetsFromDirectory(resolvedPath)
+		targets, diagnostics, err = getTargetsFromDirectory(resolvedPath)
 		if err != nil {
 			return err
 		}
 	} else if recursive {
 		// List targets from all justfiles in repo
-		targets, err = getAllTargetsRecursive(repoRoot)
+		targets, diagnostics, err = getAllTargetsRecursive(cmd.Context(), repoRoot)
 		if err != nil {
 			return err
 		}
@@ -93,94 +122,150 @@ func listTargets(cmd *cobra.Command, args []string) error {
 		}
 
 		dir := filepath.Dir(justfilePath)
-		targets, err = getTargetsFromDirectory(dir)
+		targets, diagnostics, err = getTargetsFromDirectory(dir)
 		if err != nil {
 			return err
 		}
 	}
 
-	return outputTargets(targets)
+	if err := outputTargets(repoRoot, targets, diagnostics); err != nil {
+		return err
+	}
+
+	if strict && len(diagnostics) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("found %d problem(s) in justfiles", len(diagnostics))
+	}
+	return nil
 }
 
-func getTargetsFromDirectory(dir string) ([]TargetInfo, error) {
+func getTargetsFromDirectory(dir string) ([]TargetInfo, []justfile.Diagnostic, error) {
 	justfilePath, err := justfile.FindJustfile(dir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	targets, err := justfile.GetTargets(justfilePath)
+	jf, err := justfile.Parse(justfilePath)
 	if err != nil {
-		targets, err = justfile.GetTargetsFromFile(justfilePath)
-		if err != nil {
-			return nil, err
-		}
+		return nil, nil, err
 	}
 
+	return newTargetInfos(justfilePath, jf.Targets), jf.Diagnostics, nil
+}
+
+// newTargetInfos converts parsed targets from justfilePath into list entries
+func newTargetInfos(justfilePath string, targets []justfile.Target) []TargetInfo {
+	dir := filepath.Dir(justfilePath)
+
 	var targetInfos []TargetInfo
 	for _, target := range targets {
 		targetInfos = append(targetInfos, TargetInfo{
-			Name:        target.Name,
-			Description: target.Description,
-			Directory:   dir,
+			Name:         target.Name,
+			Description:  target.Description,
+			Directory:    dir,
 			JustfilePath: justfilePath,
+			Line:         target.Line,
 		})
 	}
 
-	return targetInfos, nil
+	return targetInfos
 }
 
-func getAllTargetsRecursive(repoRoot string) ([]TargetInfo, error) {
-	var allTargets []TargetInfo
+func getAllTargetsRecursive(ctx context.Context, repoRoot string) ([]TargetInfo, []justfile.Diagnostic, error) {
+	files, err := justfile.ParseAll(ctx, repoRoot)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip errors, continue walking
-		}
-
-		// Skip hidden directories and common build/cache directories
-		if info.IsDir() {
-			baseName := filepath.Base(path)
-			if strings.HasPrefix(baseName, ".") ||
-				baseName == "node_modules" ||
-				baseName == "target" ||
-				baseName == "dist" ||
-				baseName == "build" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
+	aliasNames := repo.AliasNames(repo.AliasesFromFiles(repoRoot, files))
 
-		// Check if this is a justfile
-		if filepath.Base(path) == "justfile" {
-			dir := filepath.Dir(path)
-			targets, err := getTargetsFromDirectory(dir)
-			if err != nil {
-				// Skip directories with problematic justfiles
-				return nil
+	var allTargets []TargetInfo
+	var diagnostics []justfile.Diagnostic
+	for _, file := range files {
+		// Keep whatever targets could be parsed from problematic justfiles
+		infos := newTargetInfos(file.Path, file.Targets)
+		if relPath, err := filepath.Rel(repoRoot, filepath.Dir(file.Path)); err == nil {
+			for i := range infos {
+				infos[i].Aliases = aliasNames[filepath.ToSlash(relPath)]
 			}
-			allTargets = append(allTargets, targets...)
 		}
+		allTargets = append(allTargets, infos...)
+		diagnostics = append(diagnostics, file.Diagnostics...)
+	}
 
-		return nil
-	})
-
-	return allTargets, err
+	return allTargets, diagnostics, nil
 }
 
-func outputTargets(targets []TargetInfo) error {
+func outputTargets(repoRoot string, targets []TargetInfo, diagnostics []justfile.Diagnostic) error {
 	switch outputFormat {
 	case "json":
+		// Always emit arrays (never null) so consumers get a stable schema
+		if targets == nil {
+			targets = []TargetInfo{}
+		}
 		encoder := json.NewEncoder(os.Stdout)
 		encoder.SetIndent("", "  ")
-		return encoder.Encode(targets)
+		if !withErrors {
+			for _, d := range diagnostics {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", d.Error())
+			}
+			return encoder.Encode(targets)
+		}
+
+		output := listOutput{Targets: targets, Errors: diagnostics}
+		if output.Errors == nil {
+			output.Errors = []justfile.Diagnostic{}
+		}
+		return encoder.Encode(output)
 	case "table":
+		for _, d := range diagnostics {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", d.Error())
+		}
+
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 		fmt.Fprintln(w, "TARGET\tDESCRIPTION\tDIRECTORY")
 		for _, target := range targets {
-			fmt.Fprintf(w, "%s\t%s\t%s\n", target.Name, target.Description, target.Directory)
+			directory := target.Directory
+			for _, alias := range target.Aliases {
+				directory += " @" + alias
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", target.Name, target.Description, directory)
+		}
+		return w.Flush()
+	case "fzf":
+		color, err := ui.ColorEnabled(os.Stdout, listColor)
+		if err != nil {
+			return err
+		}
+		for _, d := range diagnostics {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", d.Error())
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		for _, target := range targets {
+			fmt.Fprintln(w, fzfLine(repoRoot, target, color))
 		}
 		return w.Flush()
 	default:
 		return fmt.Errorf("unsupported output format: %s", outputFormat)
 	}
-}
\ No newline at end of file
+}
+
+// fzfLine formats target for `j list --format fzf`: its qualified name (or
+// just its name outside a repository), directory and description separated
+// by tabs, and colored if color is set
+func fzfLine(repoRoot string, target TargetInfo, color bool) string {
+	name, dir := target.Name, target.Directory
+	if repoPath := canonicalRepoPath(repoRoot, target.Directory); repoPath != "" {
+		name = argv.Qualify(strings.TrimPrefix(repoPath, "@"), target.Name)
+		dir = repoPath
+	}
+
+	fields := []string{name, dir, target.Description}
+	if color {
+		fields[0] = ui.Paint(fields[0], ui.Bold, ui.Cyan)
+		fields[1] = ui.Paint(fields[1], ui.Blue)
+		fields[2] = ui.Paint(fields[2], ui.Dim)
+	}
+	return strings.Join(fields, "\t")
+}
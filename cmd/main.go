@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/argv"
 	"github.com/sleexyz/j/internal/completion"
 )
 
@@ -17,14 +22,27 @@ var rootCmd = &cobra.Command{
 	Use:   "j [target] [@path] [args...]",
 	Short: "Run justfile targets from anywhere in your repo",
 	Long: `j runs justfile targets from anywhere in your repository.
-Use @path syntax to run targets in specific directories.`,
+Use @path syntax to run targets in specific directories.
+
+Separate several targets with +. A + is only passed to a recipe as an
+argument after --; everything after -- goes to the last target.`,
 	Version: version,
 	Args: cobra.MinimumNArgs(0),
 	Example: `  j build                           # Run build target in current directory or repo root
   j dev @frontend                  # Run dev target in frontend directory
   j test @backend api              # Run test target in backend directory with 'api' argument
+  j frontend:dev                   # Same as 'j dev @frontend', as a single word
+  j build + test @backend          # Run build, then test
+  j calc -- 1 + 2                  # Pass a literal + to the recipe
   j list                           # List all available targets
-  j list @service                  # List targets in service directory`,
+  j list @service                  # List targets in service directory
+  j which build                    # Show where 'j build' resolves
+  j show build                     # Print the build recipe's source
+  j --pick                         # Choose a recipe interactively
+  j --pick --fzf                   # Choose a recipe with fzf
+  j history                        # Show recent runs
+  j again                          # Re-run the last command from anywhere
+  j doctor                         # Diagnose setup problems`,
 }
 
 func init() {
@@ -33,6 +51,10 @@ func init() {
 	// Add run command flags to root command
 	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output")
 	rootCmd.Flags().StringVarP(&directory, "directory", "d", "", "run in specific directory")
+	rootCmd.Flags().BoolVar(&noValidate, "no-validate", false, "don't check recipe arguments before running just")
+	rootCmd.Flags().BoolVar(&pickFlag, "pick", false, "choose a recipe interactively")
+	rootCmd.Flags().BoolVar(&printFlag, "print", false, "with --pick, print the chosen command instead of running it")
+	rootCmd.Flags().BoolVar(&fzfFlag, "fzf", false, "with --pick, choose with fzf instead of the built-in picker")
 	
 	// Add -l flag for just compatibility (acts like "j list")
 	var listFlag bool
@@ -41,14 +63,33 @@ func init() {
 	// Disable flag parsing after the first non-flag argument to pass flags through to just command
 	rootCmd.DisableFlagParsing = false // We need this false to allow our own flags
 	rootCmd.FParseErrWhitelist.UnknownFlags = true
+	// Flags after the target belong to the recipe
+	rootCmd.Flags().SetInterspersed(false)
 	
 	// Set run logic to handle -l flag
 	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		if listFlag {
 			return listTargets(cmd, args)
 		}
-		// If no arguments provided, show usage
-		if len(args) == 0 {
+		_, err := argv.Parse(os.Args[1:])
+		if pickFlag || printFlag || fzfFlag {
+			cmd.SilenceUsage = true
+			switch {
+			case err == nil:
+				return fmt.Errorf("--pick, --print and --fzf choose the recipe themselves; remove the target")
+			case !errors.Is(err, argv.ErrNoTarget):
+				return err
+			}
+			if fzfFlag {
+				return runFzf(cmd)
+			}
+			return runPicker(cmd)
+		}
+		// If no target was given, open the picker or show usage
+		if errors.Is(err, argv.ErrNoTarget) {
+			if pickByDefault() {
+				return runPicker(cmd)
+			}
 			return cmd.Help()
 		}
 		// Otherwise, run the target
@@ -59,10 +100,22 @@ func init() {
 	runCmd.Hidden = true
 	listCmd.Hidden = true
 	completionCmd.Hidden = true
+	doctorCmd.Hidden = true
+	whichCmd.Hidden = true
+	showCmd.Hidden = true
+	historyCmd.Hidden = true
+	againCmd.Hidden = true
+	pickCmd.Hidden = true
 	
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(whichCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(againCmd)
+	rootCmd.AddCommand(pickCmd)
 	
 	// Make run the default command when no subcommand is specified
 	// This will be overridden in init() to handle the -l flag
@@ -79,13 +132,38 @@ func init() {
 		return runCmd.ValidArgsFunction(cmd, args, toComplete)
 	}
 	
+	// main prints errors itself. Without this cobra prints each one first, so
+	// every error, including a failed argument check's multi-line usage
+	// message, appeared twice.
+	rootCmd.SilenceErrors = true
+	
 	// Disable built-in help and completion subcommands from appearing in completions
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
-	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+	helpCmd := &cobra.Command{Hidden: true}
+	rootCmd.SetHelpCommand(helpCmd)
+	// Cobra offers the help command as a completion even when it's hidden,
+	// which for this unnamed one is an empty candidate, so take it out of the
+	// tree while completing
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+			cmd.Root().RemoveCommand(helpCmd)
+		}
+	}
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel in-flight work (e.g. repo-wide parsing during completion) when the
+	// shell gives up on us
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	err := rootCmd.ExecuteContext(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
+
+	if interrupted && errors.Is(err, context.Canceled) {
+		// Exit quietly, the way the shell reports a command killed by Ctrl-C
+		os.Exit(130)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
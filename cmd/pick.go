@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/config"
+	"github.com/sleexyz/j/internal/history"
+	"github.com/sleexyz/j/internal/justfile"
+	"github.com/sleexyz/j/internal/picker"
+	"github.com/sleexyz/j/internal/repo"
+	"github.com/sleexyz/j/internal/ui"
+)
+
+var (
+	pickFlag  bool
+	printFlag bool
+	fzfFlag   bool
+)
+
+var pickCmd = &cobra.Command{
+	Use:   "pick",
+	Short: "Choose a recipe to run interactively",
+	Long: `Open a full-screen picker listing every recipe in the repository with its
+directory, parameters and description, and a preview of the selected recipe's
+source. Type to filter, use the arrow keys (or Ctrl-P/Ctrl-N) to move, Enter
+to run the recipe and Esc to quit. j asks for any parameters the recipe needs
+before running it.
+
+Running ` + "`j`" + ` with no arguments in a terminal opens the picker too if
+"picker": true is set in your config.`,
+	Example: `  j pick                          # Choose a recipe and run it
+  j pick --print                  # Print the chosen command instead
+  j pick --fzf                    # Choose with fzf instead of the built-in picker
+  j pick -d services/api          # Choose from one directory's justfile
+  j --pick                        # Same as j pick`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if fzfFlag {
+			return runFzf(cmd)
+		}
+		return runPicker(cmd)
+	},
+}
+
+func init() {
+	pickCmd.Flags().BoolVar(&printFlag, "print", false, "print the chosen command instead of running it")
+	pickCmd.Flags().BoolVar(&fzfFlag, "fzf", false, "choose with fzf, previewing recipes with j show")
+	pickCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output")
+	pickCmd.Flags().StringVarP(&directory, "directory", "d", "", "only list the recipes in this directory's justfile")
+	pickCmd.Flags().BoolVar(&noValidate, "no-validate", false, "don't ask for the recipe's parameters")
+}
+
+// pickByDefault reports whether `j` with no arguments should open the picker
+// rather than show help
+func pickByDefault() bool {
+	if !canPrompt() || !ui.IsTerminal(os.Stdout) {
+		return false
+	}
+	var repoRoot string
+	if root, err := repo.DetectRepoRoot(); err == nil {
+		repoRoot = root.Path
+	}
+	cfg, err := config.Load(repoRoot)
+	return err == nil && cfg.Picker
+}
+
+// runPicker lets the user choose a recipe, asks for its parameters, and runs
+// it or prints the command that would
+func runPicker(cmd *cobra.Command) error {
+	cmd.SilenceUsage = true
+	if !canPrompt() {
+		return errors.New("the picker needs a terminal; use j list to see the recipes")
+	}
+
+	repoRoot, items, err := pickerChoices(cmd)
+	if err != nil {
+		return err
+	}
+
+	color, err := ui.ColorEnabled(os.Stderr, "auto")
+	if err != nil {
+		return err
+	}
+	item, err := picker.Pick(items, picker.Options{
+		In:    os.Stdin,
+		Out:   os.Stderr,
+		Color: color,
+		Preview: func(item picker.Item) []string {
+			lines, err := justfile.Source(item.Target)
+			if err != nil {
+				return []string{err.Error()}
+			}
+			if color {
+				lines = highlightRecipe(item.Target, lines)
+			}
+			return lines
+		},
+	})
+	if errors.Is(err, picker.ErrCancelled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return runChosen(cmd.Context(), item.Target, item.Dir, repoRoot)
+}
+
+// runChosen asks for the parameters of the recipe target chosen in a picker
+// and runs it, or prints the command that would with --print. repoPath is
+// the @path of its directory, if it's in a repository.
+func runChosen(ctx context.Context, target justfile.Target, repoPath, repoRoot string) error {
+	justfilePath := target.JustfilePath
+	calls := []justfile.Call{{Target: target.Name}}
+	if !noValidate && canPrompt() {
+		var err error
+		calls, _, err = promptMissing(ctx, justfilePath, calls)
+		if err != nil {
+			return err
+		}
+	}
+
+	line := commandLine(nil, repoPath, calls)
+	if printFlag {
+		fmt.Println(line)
+		return nil
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Running: %s\n", line)
+	}
+	if err := justfile.RunCalls(justfilePath, nil, calls, verbose && !quiet); err != nil {
+		return err
+	}
+	recordRun(&resolvedTarget{
+		Target:       target,
+		RepoRoot:     repoRoot,
+		JustfilePath: justfilePath,
+		WorkingDir:   filepath.Dir(justfilePath),
+	}, nil, calls)
+	return nil
+}
+
+// pickerChoices detects the repository root and lists the recipes to choose
+// from: every recipe in the repository, or only those in the -d directory's
+// justfile. Without a repo root, they're the current directory's.
+func pickerChoices(cmd *cobra.Command) (string, []picker.Item, error) {
+	var repoRoot string
+	root, err := detectRepoRoot()
+	if err == nil {
+		repoRoot = root.Path
+	} else if !errors.Is(err, repo.ErrNoRepoRoot) {
+		return "", nil, err
+	}
+
+	items, err := pickerItems(cmd.Context(), repoRoot, directory)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(items) == 0 {
+		return "", nil, errors.New("no recipes found")
+	}
+	return repoRoot, items, nil
+}
+
+// pickerItems lists the recipes in the justfile in dir, or if dir is "",
+// every recipe in the repository (or the current directory's justfile if
+// there's no repository root), ranked by history
+func pickerItems(ctx context.Context, repoRoot, dir string) ([]picker.Item, error) {
+	var files []justfile.FileTargets
+	switch {
+	case dir != "":
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		justfilePath, err := justfile.FindJustfile(dir)
+		if err != nil {
+			return nil, err
+		}
+		jf, err := justfile.Parse(justfilePath)
+		if err != nil {
+			return nil, err
+		}
+		files = []justfile.FileTargets{{Path: justfilePath, Targets: jf.Targets}}
+	case repoRoot != "":
+		var err error
+		files, err = justfile.ParseAll(ctx, repoRoot)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		justfilePath, err := justfile.FindBestJustfile("")
+		if err != nil {
+			return nil, err
+		}
+		jf, err := justfile.Parse(justfilePath)
+		if err != nil {
+			return nil, err
+		}
+		files = []justfile.FileTargets{{Path: justfilePath, Targets: jf.Targets}}
+	}
+
+	var frecency *history.Frecency
+	if cfg, err := config.Load(repoRoot); err == nil && !cfg.DisableHistory {
+		if entries, err := history.Load(); err == nil {
+			frecency = history.NewFrecency(entries, time.Now())
+		}
+	}
+
+	var items []picker.Item
+	for _, file := range files {
+		dir := canonicalRepoPath(repoRoot, filepath.Dir(file.Path))
+		for _, target := range file.Targets {
+			items = append(items, picker.Item{
+				Target:   target,
+				Dir:      dir,
+				Frecency: frecency.Target(file.Path, target.Name),
+			})
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sleexyz/j/internal/argv"
+	"github.com/sleexyz/j/internal/justfile"
+	"github.com/sleexyz/j/internal/ui"
+)
+
+// canPrompt reports whether j can ask the user for missing arguments
+func canPrompt() bool {
+	return ui.IsTerminal(os.Stdin) && ui.IsTerminal(os.Stderr)
+}
+
+// promptMissing asks for the parameters each call is missing, up to the last
+// required one. It reports whether anything was asked. Cancelling ctx, as
+// Ctrl-C does, abandons the prompt.
+func promptMissing(ctx context.Context, justfilePath string, calls []justfile.Call) ([]justfile.Call, bool, error) {
+	reader := bufio.NewReader(os.Stdin)
+	prompted := false
+
+	for i, call := range calls {
+		target, err := justfile.LookupTarget(justfilePath, call.Target)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var argsErr *justfile.ArgsError
+		if err := justfile.CheckArgs(target, call.Args); !errors.As(err, &argsErr) || len(argsErr.Missing) == 0 {
+			continue
+		}
+
+		// Earlier optional parameters need values too, since arguments are
+		// positional; they default when left empty
+		lastRequired := slices.IndexFunc(target.Params, func(p justfile.Param) bool {
+			return p.Name == argsErr.Missing[len(argsErr.Missing)-1].Name
+		})
+
+		args := slices.Clone(call.Args)
+		for _, param := range target.Params[len(args) : lastRequired+1] {
+			values, err := promptParam(ctx, reader, target, param)
+			if err != nil {
+				return nil, false, err
+			}
+			args = append(args, values...)
+		}
+		calls[i].Args = args
+		prompted = true
+	}
+
+	return calls, prompted, nil
+}
+
+// promptParam asks for one parameter's value until it gets a valid one. A
+// variadic parameter takes several space-separated values.
+func promptParam(ctx context.Context, reader *bufio.Reader, target justfile.Target, param justfile.Param) ([]string, error) {
+	choices := target.Choices(param.Name)
+
+	label := param.Name
+	if color, _ := ui.ColorEnabled(os.Stderr, "auto"); color {
+		label = ui.Paint(label, ui.Bold)
+	}
+	if len(choices) > 0 {
+		for i, choice := range choices {
+			fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, choice)
+		}
+		label += " (" + strings.Join(choices, "/") + ")"
+	}
+	if param.HasDefault {
+		label += " [" + param.Default + "]"
+	}
+
+	for {
+		fmt.Fprintf(os.Stderr, "%s %s: ", target.Name, label)
+		line, err := readLine(ctx, reader)
+		if errors.Is(err, io.EOF) && line == "" {
+			fmt.Fprintln(os.Stderr)
+			return nil, fmt.Errorf("no value given for '%s'", param.Name)
+		} else if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		answer := strings.TrimSpace(line)
+		switch {
+		case answer == "" && param.HasDefault:
+			return []string{param.Default}, nil
+		case answer == "":
+			continue
+		case len(choices) > 0:
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+				answer = choices[n-1]
+			}
+			if !slices.Contains(choices, answer) {
+				fmt.Fprintf(os.Stderr, "choose one of: %s\n", strings.Join(choices, ", "))
+				continue
+			}
+		}
+
+		if param.Variadic != "" {
+			return strings.Fields(answer), nil
+		}
+		return []string{answer}, nil
+	}
+}
+
+// readLine reads a line from reader, giving up when ctx is cancelled. main
+// traps Ctrl-C to cancel ctx, so a read that ignored it couldn't be
+// interrupted.
+func readLine(ctx context.Context, reader *bufio.Reader) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := reader.ReadString('\n')
+		done <- result{line, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.line, r.err
+	case <-ctx.Done():
+		fmt.Fprintln(os.Stderr)
+		return "", ctx.Err()
+	}
+}
+
+// commandLine formats the j command equivalent to running calls, so a run
+// that prompted for arguments can be repeated from shell history. The last
+// call's arguments follow "--" if j would otherwise read any of them as
+// something else; "--" can't do that for the arguments of earlier calls.
+func commandLine(justFlags []string, repoPath string, calls []justfile.Call) string {
+	words := []string{"j"}
+	words = append(words, justFlags...)
+	for i, call := range calls {
+		if i > 0 {
+			words = append(words, "+")
+		}
+		words = append(words, call.Target)
+		if i == 0 && repoPath != "" {
+			words = append(words, repoPath)
+		}
+		if i == len(calls)-1 && slices.ContainsFunc(call.Args, argv.NeedsSeparator) {
+			words = append(words, "--")
+		}
+		words = append(words, call.Args...)
+	}
+
+	for i, word := range words {
+		words[i] = shellQuote(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes word for a POSIX shell if it needs it
+func shellQuote(word string) string {
+	if word != "" && strings.IndexFunc(word, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r))
+	}) < 0 {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
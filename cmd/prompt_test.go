@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sleexyz/j/internal/argv"
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+// shellWords splits a command line the way a POSIX shell would, for the
+// quoting shellQuote produces
+func shellWords(line string) []string {
+	var words []string
+	var word strings.Builder
+	inWord, quoted := false, false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quoted && c == '\'':
+			quoted = false
+		case quoted:
+			word.WriteByte(c)
+		case c == '\'':
+			quoted, inWord = true, true
+		case c == '\\' && i+1 < len(line):
+			i++
+			word.WriteByte(line[i])
+			inWord = true
+		case c == ' ':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+func TestCommandLineRoundTrip(t *testing.T) {
+	tests := []struct {
+		justFlags []string
+		repoPath  string
+		calls     []justfile.Call
+		want      string
+	}{
+		{
+			repoPath: "@",
+			calls:    []justfile.Call{{Target: "calc", Args: []string{"1", "+", "2"}}},
+			want:     "j calc @ -- 1 + 2",
+		},
+		{
+			calls: []justfile.Call{{Target: "deploy", Args: []string{"@prod", "--"}}},
+			want:  "j deploy -- @prod --",
+		},
+		{
+			repoPath: "@services/api",
+			calls:    []justfile.Call{{Target: "serve", Args: []string{"--set", "--dry-run", "-n"}}},
+			want:     "j serve @services/api -- --set --dry-run -n",
+		},
+		{
+			justFlags: []string{"--set", "name", "two words"},
+			calls:     []justfile.Call{{Target: "greet", Args: []string{"-v", "it's"}}},
+			want:      `j --set name 'two words' greet -v 'it'\''s'`,
+		},
+		{
+			calls: []justfile.Call{
+				{Target: "build", Args: []string{"x"}},
+				{Target: "test", Args: []string{"a", "+"}},
+			},
+			want: "j build x + test -- a +",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			line := commandLine(tt.justFlags, tt.repoPath, tt.calls)
+			if line != tt.want {
+				t.Errorf("commandLine() = %s, want %s", line, tt.want)
+			}
+
+			inv, err := argv.Parse(shellWords(line)[1:])
+			if err != nil {
+				t.Fatalf("%s doesn't parse: %v", line, err)
+			}
+			if fmt.Sprint(inv.JustFlags) != fmt.Sprint(tt.justFlags) || inv.RepoPath != tt.repoPath || fmt.Sprint(inv.Calls) != fmt.Sprint(tt.calls) {
+				t.Errorf("%s parses as %v %s %v, want %v %s %v", line, inv.JustFlags, inv.RepoPath, inv.Calls, tt.justFlags, tt.repoPath, tt.calls)
+			}
+		})
+	}
+}
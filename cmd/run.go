@@ -1,19 +1,23 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/argv"
 	"github.com/sleexyz/j/internal/completion"
 	"github.com/sleexyz/j/internal/justfile"
 	"github.com/sleexyz/j/internal/repo"
 )
 
 var (
-	quiet     bool
-	directory string
+	quiet      bool
+	directory  string
+	noValidate bool
 )
 
 var runCmd = &cobra.Command{
@@ -23,105 +27,222 @@ var runCmd = &cobra.Command{
 
 The target is the name of the justfile target to execute.
 The optional @path argument specifies a subdirectory within the repository.
-Additional arguments are passed through to the justfile target.`,
+Additional arguments are passed through to the justfile target.
+
+A + separates several targets to run in one go. To pass a literal + (or an
+argument starting with -) to a recipe, put it after --, which ends the
+command line: everything after it goes to the last target.`,
 	Example: `  j run build                      # Run build target
   j run dev @frontend             # Run dev target in frontend directory
   j run test @backend api         # Run test target in backend directory with 'api' argument
+  j run build + test @backend     # Run build, then test
+  j run calc -- 1 + 2             # Pass a literal + to the recipe
   j build                         # Shorthand (run is default command)
   j dev @frontend                 # Shorthand syntax`,
-	Args: cobra.MinimumNArgs(1),
+	// The target is checked by parseInvocation, since cobra may have taken
+	// it as the value of a just flag
+	Args: cobra.ArbitraryArgs,
 	RunE: runTarget,
 }
 
 func init() {
 	runCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output")
 	runCmd.Flags().StringVarP(&directory, "directory", "d", "", "run in specific directory")
+	runCmd.Flags().BoolVar(&noValidate, "no-validate", false, "don't check recipe arguments before running just")
 	
 	// Ignore unknown flags so they can be passed through to the inner just command
 	runCmd.FParseErrWhitelist.UnknownFlags = true
+	// Flags after the target belong to the recipe
+	runCmd.Flags().SetInterspersed(false)
 	
 	// Set up completion functions
 	runCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
 			// Complete target names
 			return completion.CompleteTargets(cmd, args, toComplete)
-		} else if len(args) == 1 {
-			// Complete @path filtered by the target in args[0]
-			return completion.CompletePathsWithTarget(cmd, args, toComplete, args[0])
 		}
-		return nil, cobra.ShellCompDirectiveNoFileComp
+		// Complete the @path filtered by the target, and recipe arguments
+		return completion.CompleteArgs(cmd, args, toComplete)
+	}
+}
+
+// parseInvocation re-parses the command line j was run with. Cobra only sees
+// j's flags before the target; it can't tell just's flags, the @path and
+// recipe arguments apart.
+func parseInvocation(cmd *cobra.Command) (*argv.Invocation, error) {
+	var command string
+	if cmd.HasParent() {
+		// `j run ...`
+		command = cmd.Name()
+	}
+
+	inv, err := argv.ParseCommand(os.Args[1:], command)
+	if err != nil {
+		return nil, err
+	}
+
+	quiet = quiet || inv.Quiet
+	verbose = verbose || inv.Verbose
+	noValidate = noValidate || inv.NoValidate
+	if inv.Directory != "" {
+		directory = inv.Directory
 	}
+	return inv, nil
 }
 
-// findOriginalArgs reconstructs the original command arguments from os.Args
-// to capture flags and arguments that Cobra may have consumed or reordered
-func findOriginalArgs(parsedArgs []string) []string {
-	// Start from os.Args[1:] (skip program name)
-	rawArgs := os.Args[1:]
+func runTarget(cmd *cobra.Command, args []string) error {
+	inv, err := parseInvocation(cmd)
+	if err != nil {
+		return err
+	}
+	// From here on, errors explain themselves better than j's usage would
+	cmd.SilenceUsage = true
+	
+	resolved, err := resolveTarget(inv.Calls[0].Target, inv.RepoPath, inv.Alias)
+	if err != nil {
+		return err
+	}
 	
-	// Find where the first non-flag argument (target) appears
-	var targetIndex = -1
-	for i, arg := range rawArgs {
-		if !strings.HasPrefix(arg, "-") && arg != "run" {
-			targetIndex = i
-			break
+	// Every target runs from the first one's justfile
+	calls, err := justfile.PlanCalls(resolved.JustfilePath, inv.Calls)
+	if err != nil {
+		return err
+	}
+	
+	flags, repoPath := commandDirectory(inv, resolved)
+	if !noValidate && canPrompt() {
+		var prompted bool
+		calls, prompted, err = promptMissing(cmd.Context(), resolved.JustfilePath, calls)
+		if err != nil {
+			return err
+		}
+		if prompted {
+			fmt.Fprintf(os.Stderr, "Running: %s\n", commandLine(flags, repoPath, calls))
 		}
 	}
 	
-	if targetIndex >= 0 {
-		// Return everything starting from the target, but filter out known j flags
-		result := rawArgs[targetIndex:]
-		filtered := make([]string, 0, len(result))
-		
-		for i := 0; i < len(result); i++ {
-			arg := result[i]
-			// Skip known j flags and their values
-			if arg == "--quiet" || arg == "-q" || 
-			   arg == "--verbose" || arg == "-v" || strings.HasPrefix(arg, "--directory") || arg == "-d" {
-				// Skip this flag and its value if it takes one
-				if (arg == "--directory" || arg == "-d") && i+1 < len(result) && !strings.HasPrefix(result[i+1], "-") {
-					i++ // Skip the directory value too
-				}
-				continue
-			}
-			filtered = append(filtered, arg)
+	if !noValidate {
+		if err := checkCalls(resolved.JustfilePath, repoPath, calls); err != nil {
+			return err
 		}
-		return filtered
 	}
 	
-	// Fallback to parsed args if we can't find the target in raw args
-	return parsedArgs
+	if err := justfile.RunCalls(resolved.JustfilePath, inv.JustFlags, calls, verbose && !quiet); err != nil {
+		return err
+	}
+	recordRun(resolved, inv.JustFlags, calls)
+	return nil
 }
 
-func runTarget(cmd *cobra.Command, args []string) error {
-	// Get original arguments from os.Args to capture all flags and arguments
-	// Skip the program name and any global flags processed by Cobra
-	originalArgs := findOriginalArgs(args)
-	
-	target := originalArgs[0]
-	var repoPath string
-	var extraArgs []string
-	
-	// Parse arguments to separate target, path, and extra args
-	for i, arg := range originalArgs[1:] {
-		if strings.HasPrefix(arg, "@") {
-			repoPath = arg
-			// Everything after the path becomes extra args
-			if i+2 < len(originalArgs) {
-				extraArgs = originalArgs[i+2:]
-			}
-			break
-		} else if repoPath == "" {
-			// If no @path found yet, treat remaining args as extra args
-			extraArgs = originalArgs[1:]
-			break
+// commandDirectory returns the flags and @path that select resolved's
+// directory in a printed command line. A directory chosen with -d or an
+// alias qualified target is given as its @path, so the command works from
+// anywhere in the repository, or as -d DIR outside one.
+func commandDirectory(inv *argv.Invocation, resolved *resolvedTarget) (flags []string, repoPath string) {
+	if inv.RepoPath != "" && !inv.Alias || inv.RepoPath == "" && directory == "" {
+		return inv.JustFlags, inv.RepoPath
+	}
+	if repoPath := canonicalRepoPath(resolved.RepoRoot, resolved.WorkingDir); repoPath != "" {
+		return inv.JustFlags, repoPath
+	}
+	return append([]string{"-d", directory}, inv.JustFlags...), ""
+}
+
+// checkCalls checks each call's arguments against its recipe's parameters,
+// explaining how to call the recipe if they don't fit
+func checkCalls(justfilePath, repoPath string, calls []justfile.Call) error {
+	for _, call := range calls {
+		target, err := justfile.LookupTarget(justfilePath, call.Target)
+		if err != nil {
+			return err
+		}
+		if err := justfile.CheckArgs(target, call.Args); err != nil {
+			usage := strings.Join(strings.Fields("j "+target.Name+" "+repoPath+" "+target.Usage()), " ")
+			return fmt.Errorf("%w\nusage: %s\n(use --no-validate to run just anyway)", err, usage)
 		}
 	}
-	
-	// Find repository root
-	repoRoot, err := repo.FindRepoRoot()
+	return nil
+}
+
+// detectRepoRoot finds the repository root, reporting which detector found
+// it in verbose mode
+func detectRepoRoot() (*repo.Root, error) {
+	root, err := repo.DetectRepoRoot()
 	if err != nil {
-		return fmt.Errorf("failed to find repository root: %w", err)
+		return nil, err
+	}
+	if verbose && !quiet {
+		fmt.Fprintf(os.Stderr, "Repo root: %s (detected by %s)\n", root.Path, root.Detector)
+		if root.Inner != root.Path {
+			fmt.Fprintf(os.Stderr, "Innermost repo: %s (use @@path or @/path)\n", root.Inner)
+		}
+	}
+	return root, nil
+}
+
+// resolveRepoPath resolves an @path, or an alias if alias is set, telling
+// the user which directory an abbreviated @path matched
+func resolveRepoPath(root *repo.Root, repoPath string, alias bool) (string, error) {
+	lookup := root.Lookup
+	if alias {
+		lookup = root.LookupAlias
+	}
+	resolution, err := lookup(repoPath)
+	if errors.Is(err, repo.ErrNoAlias) {
+		return "", fmt.Errorf("%w (j reads name::recipe as an alias, not a just module)", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if resolution.Abbreviated && !quiet {
+		fmt.Fprintf(os.Stderr, "Resolved %s to %s\n", repoPath, resolution.RepoPath)
+	}
+	return resolution.Path, nil
+}
+
+// targetArgs reads the `<target> [@path]` arguments of commands like which
+// and show. The target may be qualified, like services/api:build, or by an
+// alias, like api::build.
+func targetArgs(args []string) (target, repoPath string, alias bool, err error) {
+	target = args[0]
+	if len(args) == 2 {
+		repoPath = args[1]
+	}
+
+	qualifiedPath, name, alias, ok := argv.SplitQualified(target)
+	if !ok {
+		return target, repoPath, false, nil
+	}
+	if repoPath != "" && (repoPath != qualifiedPath || alias) {
+		return "", "", false, fmt.Errorf("more than one @path (%s and %s)", qualifiedPath, repoPath)
+	}
+	if name == "" {
+		return "", "", false, fmt.Errorf("%w after %q", argv.ErrNoTarget, target)
+	}
+	return name, qualifiedPath, alias, nil
+}
+
+// resolvedTarget describes exactly where `j <target>` runs
+type resolvedTarget struct {
+	Target       justfile.Target
+	RepoRoot     string
+	JustfilePath string
+	WorkingDir   string
+}
+
+// resolveTarget applies j's resolution rules: an explicit @path (an alias
+// if alias is set), then the -d/--directory flag, then the best justfile for the current directory.
+// It validates that the target exists in the chosen justfile. Both running
+// and `j which` go through here so they can never disagree.
+func resolveTarget(target, repoPath string, alias bool) (*resolvedTarget, error) {
+	// Find repository root. Without one, only the current directory's
+	// justfile and -d can be used.
+	var repoRoot string
+	root, err := detectRepoRoot()
+	if err == nil {
+		repoRoot = root.Path
+	} else if !errors.Is(err, repo.ErrNoRepoRoot) || repoPath != "" {
+		return nil, fmt.Errorf("failed to find repository root: %w", err)
 	}
 	
 	var workingDir string
@@ -130,39 +251,45 @@ func runTarget(cmd *cobra.Command, args []string) error {
 	if repoPath != "" {
 		// Handle @path syntax
 		if !strings.HasPrefix(repoPath, "@") {
-			return fmt.Errorf("path must start with @, got: %s", repoPath)
+			return nil, fmt.Errorf("path must start with @, got: %s", repoPath)
 		}
 		
-		resolvedPath, err := repo.ResolveRepoPath(repoPath, repoRoot)
+		resolvedPath, err := resolveRepoPath(root, repoPath, alias)
 		if err != nil {
-			return fmt.Errorf("failed to resolve path %s: %w", repoPath, err)
+			return nil, err
 		}
 		
 		workingDir = resolvedPath
 		justfilePath, err = justfile.FindJustfile(workingDir)
 		if err != nil {
-			return fmt.Errorf("no justfile found in %s", workingDir)
+			return nil, fmt.Errorf("no justfile found in %s", workingDir)
 		}
 	} else if directory != "" {
 		// Handle -d/--directory flag
 		workingDir = directory
 		justfilePath, err = justfile.FindJustfile(workingDir)
 		if err != nil {
-			return fmt.Errorf("no justfile found in %s", workingDir)
+			return nil, fmt.Errorf("no justfile found in %s", workingDir)
 		}
 	} else {
 		// Find the best justfile (current dir or repo root)
 		justfilePath, err = justfile.FindBestJustfile(repoRoot)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	
 	// Validate that the target exists
-	if err := justfile.ValidateTarget(justfilePath, target); err != nil {
-		return err
+	t, err := justfile.LookupTarget(justfilePath, target)
+	if err != nil {
+		return nil, err
 	}
 	
-	// Run the target with extra args
-	return justfile.RunTarget(justfilePath, target, extraArgs, verbose && !quiet)
-}
\ No newline at end of file
+	// just runs recipes from the directory containing the justfile
+	return &resolvedTarget{
+		Target:       t,
+		RepoRoot:     repoRoot,
+		JustfilePath: justfilePath,
+		WorkingDir:   filepath.Dir(justfilePath),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/justfile"
+	"github.com/sleexyz/j/internal/ui"
+)
+
+var showColor string
+
+// interpolationPattern matches {{ ... }} interpolations in recipe bodies
+var interpolationPattern = regexp.MustCompile(`\{\{.*?\}\}`)
+
+var showCmd = &cobra.Command{
+	Use:   "show <target> [@path]",
+	Short: "Print a recipe's source",
+	Long: `Print the full source of a recipe (attributes, doc comment, signature and body)
+for the target that ` + "`j <target>`" + ` would run, like ` + "`just --show`" + `.
+Output is syntax highlighted when writing to a terminal.`,
+	Example: `  j show build                    # Show the build recipe j would run from here
+  j show dev @frontend            # Show the dev recipe in frontend directory
+  j show api::test                # Show the test recipe in the directory aliased api
+  j show test --color never       # Disable syntax highlighting`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: showTarget,
+}
+
+func init() {
+	showCmd.Flags().StringVarP(&directory, "directory", "d", "", "resolve in specific directory")
+	showCmd.Flags().StringVar(&showColor, "color", "auto", "highlight output (auto, always, never)")
+	showCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return runCmd.ValidArgsFunction(cmd, args, toComplete)
+	}
+}
+
+func showTarget(cmd *cobra.Command, args []string) error {
+	target, repoPath, alias, err := targetArgs(args)
+	if err != nil {
+		return err
+	}
+
+	color, err := ui.ColorEnabled(os.Stdout, showColor)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolveTarget(target, repoPath, alias)
+	if err != nil {
+		return err
+	}
+
+	lines, err := justfile.Source(resolved.Target)
+	if err != nil {
+		return err
+	}
+
+	if color {
+		lines = highlightRecipe(resolved.Target, lines)
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// highlightRecipe colors the source lines of target for a terminal
+func highlightRecipe(target justfile.Target, lines []string) []string {
+	highlighted := make([]string, len(lines))
+	for i, line := range lines {
+		lineNum := target.StartLine + i
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case lineNum < target.Line && strings.HasPrefix(trimmed, "["):
+			highlighted[i] = ui.Paint(line, ui.Magenta)
+		case lineNum < target.Line:
+			highlighted[i] = ui.Paint(line, ui.Dim)
+		case lineNum == target.Line:
+			highlighted[i] = highlightHeader(target.Name, line)
+		case strings.HasPrefix(trimmed, "#"):
+			// Body comments and shebang lines
+			highlighted[i] = ui.Paint(line, ui.Dim)
+		default:
+			highlighted[i] = interpolationPattern.ReplaceAllStringFunc(line, func(s string) string {
+				return ui.Paint(s, ui.Yellow)
+			})
+		}
+	}
+	return highlighted
+}
+
+// highlightHeader colors the recipe name, parameters and dependencies
+func highlightHeader(name, line string) string {
+	signature, rest, ok := justfile.SplitHeader(line)
+	if !ok {
+		return line
+	}
+
+	nameEnd := strings.Index(signature, name) + len(name)
+	params := signature[nameEnd:]
+	return ui.Paint(signature[:nameEnd], ui.Bold, ui.Cyan) + ui.Paint(params, ui.Yellow) + ":" + ui.Paint(rest, ui.Blue)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+var whichFormat string
+
+// whichOutput is the JSON document printed by `j which --format json`
+type whichOutput struct {
+	Target       string       `json:"target"`
+	JustfilePath string       `json:"justfile_path"`
+	WorkingDir   string       `json:"working_directory"`
+	Line         int          `json:"line"`
+	Signature    string       `json:"signature"`
+	Description  string       `json:"description,omitempty"`
+	Others       []TargetInfo `json:"others"`
+}
+
+var whichCmd = &cobra.Command{
+	Use:   "which <target> [@path]",
+	Short: "Show where a target resolves",
+	Long: `Show exactly which justfile and recipe ` + "`j <target>`" + ` would run, using the same
+resolution rules (@path, --directory, then the current directory or repo root),
+along with every other justfile in the repository that defines the same recipe.`,
+	Example: `  j which build                   # Where would 'j build' run from here?
+  j which dev @frontend           # Where would 'j dev @frontend' run?
+  j which frontend:dev            # Same, as a qualified target
+  j which test --format json      # Output as JSON`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: whichTarget,
+}
+
+func init() {
+	whichCmd.Flags().StringVarP(&directory, "directory", "d", "", "resolve in specific directory")
+	whichCmd.Flags().StringVarP(&whichFormat, "format", "f", "table", "output format (table, json)")
+	whichCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return runCmd.ValidArgsFunction(cmd, args, toComplete)
+	}
+}
+
+func whichTarget(cmd *cobra.Command, args []string) error {
+	target, repoPath, alias, err := targetArgs(args)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolveTarget(target, repoPath, alias)
+	if err != nil {
+		return err
+	}
+
+	// Find every other definition of the same recipe in the repo
+	var files []justfile.FileTargets
+	if resolved.RepoRoot != "" {
+		files, err = justfile.ParseAll(cmd.Context(), resolved.RepoRoot)
+		if err != nil {
+			return err
+		}
+	}
+
+	others := []TargetInfo{}
+	for _, file := range files {
+		if file.Path == resolved.JustfilePath {
+			continue
+		}
+		for _, info := range newTargetInfos(file.Path, file.Targets) {
+			if info.Name == resolved.Target.Name {
+				others = append(others, info)
+			}
+		}
+	}
+
+	output := whichOutput{
+		Target:       resolved.Target.Name,
+		JustfilePath: resolved.JustfilePath,
+		WorkingDir:   resolved.WorkingDir,
+		Line:         resolved.Target.Line,
+		Signature:    resolved.Target.Signature,
+		Description:  resolved.Target.Description,
+		Others:       others,
+	}
+
+	switch whichFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "target:\t%s\n", output.Target)
+		fmt.Fprintf(w, "justfile:\t%s:%d\n", output.JustfilePath, output.Line)
+		fmt.Fprintf(w, "directory:\t%s\n", output.WorkingDir)
+		fmt.Fprintf(w, "signature:\t%s\n", output.Signature)
+		if output.Description != "" {
+			fmt.Fprintf(w, "description:\t%s\n", output.Description)
+		}
+		for i, other := range others {
+			label := ""
+			if i == 0 {
+				label = "also in:"
+			}
+			fmt.Fprintf(w, "%s\t%s (%s:%d)\n", label, displayPath(resolved.RepoRoot, other.Directory), other.JustfilePath, other.Line)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported output format: %s", whichFormat)
+	}
+}
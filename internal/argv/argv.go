@@ -0,0 +1,300 @@
+package argv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+// Invocation is a parsed `j [flags] <target> [@path] [args...] [-- args...]`
+// command line
+type Invocation struct {
+	// Calls are the targets to run with their arguments, in order. There's
+	// more than one for `j build + test`.
+	Calls    []justfile.Call
+	RepoPath string
+	// Alias is set when RepoPath came from an alias qualified target like
+	// api::build, so it must resolve as an alias rather than a directory
+	Alias bool
+	// JustFlags are just's own flags and their values, passed before the recipe
+	JustFlags []string
+
+	// j's own flags
+	Quiet      bool
+	Verbose    bool
+	Directory  string
+	NoValidate bool
+	// Pick, Print and Fzf open the picker, which only makes sense without a
+	// target
+	Pick  bool
+	Print bool
+	Fzf   bool
+}
+
+// ErrNoTarget is returned when the command line doesn't name a target
+var ErrNoTarget = errors.New("missing target")
+
+// Parse parses the arguments following `j`. The grammar is:
+//
+//   - Before the target: j's flags (-q, -v, -d DIR, --no-validate, --pick,
+//     --print, --fzf; short ones combine, as in -qv), just's flags, and the
+//     @path.
+//     Any other flag is an error.
+//   - The target: the first word that isn't a flag or an @path. A qualified
+//     target like services/api:build names its @path too.
+//   - After the target: the @path if it wasn't given yet, just's long flags
+//     (--set NAME VALUE, --dry-run, ...), and recipe arguments. j's flags are
+//     recipe arguments here, so `j test -v` passes -v to the recipe.
+//   - A "+" followed by another target and its arguments, any number of times.
+//   - After "--": arguments to the last target only, taken literally.
+func Parse(args []string) (*Invocation, error) {
+	return ParseCommand(args, "")
+}
+
+// ParseCommand is Parse for the arguments following `j`, where the first word
+// is the subcommand name command (e.g. "run") rather than the target
+func ParseCommand(args []string, command string) (*Invocation, error) {
+	inv := &Invocation{}
+
+	// Set after a "+" until the next target is seen
+	wantTarget := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		beforeTarget := len(inv.Calls) == 0
+		last := len(inv.Calls) - 1
+
+		if arg == "--" {
+			if beforeTarget || wantTarget {
+				return nil, fmt.Errorf("%w before \"--\"", ErrNoTarget)
+			}
+			inv.Calls[last].Args = append(inv.Calls[last].Args, args[i+1:]...)
+			wantTarget = false
+			break
+		}
+
+		if arg == "+" && !beforeTarget {
+			if wantTarget {
+				return nil, fmt.Errorf("%w after \"+\"", ErrNoTarget)
+			}
+			wantTarget = true
+			continue
+		}
+
+		if strings.HasPrefix(arg, "@") {
+			if inv.RepoPath != "" {
+				return nil, fmt.Errorf("more than one @path (%s and %s); put recipe arguments starting with @ after --", inv.RepoPath, arg)
+			}
+			inv.RepoPath = arg
+			continue
+		}
+
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			// just's flags: long forms anywhere, short forms only before the target
+			if values, ok := justfile.LookupGlobalFlag(arg); ok && (beforeTarget || strings.HasPrefix(arg, "--")) {
+				if i+values >= len(args) {
+					return nil, fmt.Errorf("flag %s needs %d value(s)", flagName(arg), values)
+				}
+				inv.JustFlags = append(inv.JustFlags, args[i:i+values+1]...)
+				i += values
+				continue
+			}
+
+			if wantTarget {
+				return nil, fmt.Errorf("expected a target after \"+\", got %s", arg)
+			}
+			if !beforeTarget {
+				inv.Calls[last].Args = append(inv.Calls[last].Args, arg)
+				continue
+			}
+
+			consumed, err := inv.parseFlag(args[i:])
+			if err != nil {
+				return nil, err
+			}
+			i += consumed
+			continue
+		}
+
+		if command != "" {
+			// The subcommand name itself, e.g. `j run build`
+			if arg != command {
+				return nil, fmt.Errorf("expected %q, got %q", command, arg)
+			}
+			command = ""
+			continue
+		}
+
+		if beforeTarget || wantTarget {
+			target := arg
+			if repoPath, name, alias, ok := SplitQualified(arg); ok {
+				if inv.RepoPath != "" && (inv.RepoPath != repoPath || inv.Alias != alias) {
+					return nil, fmt.Errorf("more than one @path (%s and %s); every target runs from the same justfile", inv.RepoPath, arg)
+				}
+				if name == "" {
+					return nil, fmt.Errorf("%w after %q", ErrNoTarget, arg)
+				}
+				inv.RepoPath, inv.Alias, target = repoPath, alias, name
+			}
+			inv.Calls = append(inv.Calls, justfile.Call{Target: target})
+			wantTarget = false
+		} else {
+			inv.Calls[last].Args = append(inv.Calls[last].Args, arg)
+		}
+	}
+
+	if wantTarget {
+		return nil, fmt.Errorf("%w after \"+\"", ErrNoTarget)
+	}
+	if len(inv.Calls) == 0 {
+		return nil, ErrNoTarget
+	}
+	return inv, nil
+}
+
+// NeedsSeparator reports whether Parse would read arg, as a recipe argument
+// after the target, as something else: a "+", "--", an @path or one of
+// just's long flags. Such arguments only reach the recipe after "--".
+func NeedsSeparator(arg string) bool {
+	if arg == "+" || arg == "--" || strings.HasPrefix(arg, "@") {
+		return true
+	}
+	_, ok := justfile.LookupGlobalFlag(arg)
+	return ok && strings.HasPrefix(arg, "--")
+}
+
+// SplitQualified splits a qualified target like `services/api:build` or
+// `api::build` into its @path (`@services/api`, `@api`) and recipe name.
+// `:build` names the recipe in the repository root. alias is set for the
+// `api::build` form, whose @path must be resolved as an alias. ok is false if
+// word isn't qualified; recipe names can't contain colons, so any word with
+// an unescaped one is. A backslash escapes the next character of the path, so
+// `a\:b:build` names the recipe build in the directory a:b.
+//
+// just writes a recipe in a module as `module::recipe`. j doesn't know about
+// modules, so to j that form always names an alias.
+func SplitQualified(word string) (repoPath, target string, alias, ok bool) {
+	var path strings.Builder
+	for i := 0; i < len(word); i++ {
+		switch c := word[i]; {
+		case c == '\\' && i+1 < len(word):
+			i++
+			path.WriteByte(word[i])
+		case c == ':':
+			target, alias = strings.CutPrefix(word[i+1:], ":")
+			return "@" + strings.TrimPrefix(path.String(), "@"), target, alias, true
+		default:
+			path.WriteByte(c)
+		}
+	}
+	return "", "", false, false
+}
+
+// Qualify formats the recipe target in the directory relPath (relative to
+// the repository root, "." for the root itself) as a qualified target,
+// escaping colons and backslashes in relPath for SplitQualified
+func Qualify(relPath, target string) string {
+	if relPath == "." {
+		relPath = ""
+	}
+	return qualifiedPathEscaper.Replace(relPath) + ":" + target
+}
+
+var qualifiedPathEscaper = strings.NewReplacer(`\`, `\\`, ":", `\:`)
+
+// QualifyAlias formats the recipe target in the directory named by alias as
+// a qualified target, like api::build
+func QualifyAlias(alias, target string) string {
+	return alias + "::" + target
+}
+
+// parseFlag parses one of j's own flags at args[0], returning how many
+// following arguments it consumed
+func (inv *Invocation) parseFlag(args []string) (int, error) {
+	arg := args[0]
+	if !strings.HasPrefix(arg, "--") {
+		return inv.parseShortFlags(args)
+	}
+	name, value, hasValue := strings.Cut(arg, "=")
+
+	switch name {
+	case "--quiet":
+		inv.Quiet = true
+	case "--verbose":
+		inv.Verbose = true
+	case "--no-validate":
+		inv.NoValidate = true
+	case "--pick":
+		inv.Pick = true
+	case "--print":
+		inv.Print = true
+	case "--fzf":
+		inv.Fzf = true
+	case "--directory":
+		if hasValue {
+			inv.Directory = value
+			return 0, nil
+		}
+		if len(args) < 2 {
+			return 0, fmt.Errorf("flag %s needs a directory", name)
+		}
+		inv.Directory = args[1]
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("unknown flag %s (recipe arguments go after the target)", arg)
+	}
+
+	if hasValue {
+		return 0, fmt.Errorf("flag %s doesn't take a value", name)
+	}
+	return 0, nil
+}
+
+// parseShortFlags parses a group of short flags at args[0] like -q, -qv or
+// -qd DIR, returning how many following arguments it consumed. Like just's
+// own short flags, -d takes the rest of the group as its value if there is
+// any (-dDIR, -d=DIR).
+func (inv *Invocation) parseShortFlags(args []string) (int, error) {
+	arg := args[0]
+	flags := arg[1:]
+	for i, c := range flags {
+		switch c {
+		case 'q':
+			inv.Quiet = true
+		case 'v':
+			inv.Verbose = true
+		case 'd':
+			if rest := flags[i+1:]; rest != "" {
+				inv.Directory = strings.TrimPrefix(rest, "=")
+				return 0, nil
+			}
+			if len(args) < 2 {
+				return 0, fmt.Errorf("flag -d needs a directory")
+			}
+			inv.Directory = args[1]
+			return 1, nil
+		default:
+			if c == '=' && i > 0 {
+				return 0, fmt.Errorf("flag -%c doesn't take a value", flags[i-1])
+			}
+			// just's boolean short flags, like -n for --dry-run
+			if values, ok := justfile.LookupGlobalFlag("-" + string(c)); ok && values == 0 {
+				inv.JustFlags = append(inv.JustFlags, "-"+string(c))
+				continue
+			}
+			if len(flags) == 1 {
+				return 0, fmt.Errorf("unknown flag %s (recipe arguments go after the target)", arg)
+			}
+			return 0, fmt.Errorf("unknown flag -%c in %s (recipe arguments go after the target)", c, arg)
+		}
+	}
+	return 0, nil
+}
+
+// flagName strips an inline value from a flag for use in messages
+func flagName(arg string) string {
+	name, _, _ := strings.Cut(arg, "=")
+	return name
+}
@@ -0,0 +1,216 @@
+package argv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// command is the subcommand name for ParseCommand, if any
+		command string
+		want    Invocation
+	}{
+		{
+			name: "target only",
+			args: []string{"build"},
+			want: Invocation{Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "recipe arguments",
+			args: []string{"deploy", "prod", "fast"},
+			want: Invocation{Calls: []justfile.Call{{Target: "deploy", Args: []string{"prod", "fast"}}}},
+		},
+		{
+			name: "double dash passes arguments literally",
+			args: []string{"test", "--", "-v", "+", "@x"},
+			want: Invocation{Calls: []justfile.Call{{Target: "test", Args: []string{"-v", "+", "@x"}}}},
+		},
+		{
+			name: "plus separates targets",
+			args: []string{"build", "x", "+", "test", "y"},
+			want: Invocation{Calls: []justfile.Call{
+				{Target: "build", Args: []string{"x"}},
+				{Target: "test", Args: []string{"y"}},
+			}},
+		},
+		{
+			name: "double dash applies to the last target",
+			args: []string{"build", "+", "test", "--", "+"},
+			want: Invocation{Calls: []justfile.Call{
+				{Target: "build"},
+				{Target: "test", Args: []string{"+"}},
+			}},
+		},
+		{
+			name: "directory with a separate value",
+			args: []string{"-d", "sub", "build"},
+			want: Invocation{Directory: "sub", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "directory with an inline value",
+			args: []string{"-d=sub", "build"},
+			want: Invocation{Directory: "sub", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "directory attached to the flag",
+			args: []string{"-dsub", "build"},
+			want: Invocation{Directory: "sub", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "long directory flag",
+			args: []string{"--directory=sub", "build"},
+			want: Invocation{Directory: "sub", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "combined short flags",
+			args: []string{"-qv", "build"},
+			want: Invocation{Quiet: true, Verbose: true, Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "combined short flags ending in directory",
+			args: []string{"-qd", "sub", "build"},
+			want: Invocation{Quiet: true, Directory: "sub", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "combined with a just short flag",
+			args: []string{"-vn", "build"},
+			want: Invocation{Verbose: true, JustFlags: []string{"-n"}, Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "j flags after the target go to the recipe",
+			args: []string{"test", "-qv", "--pick"},
+			want: Invocation{Calls: []justfile.Call{{Target: "test", Args: []string{"-qv", "--pick"}}}},
+		},
+		{
+			name: "just flag before the target",
+			args: []string{"--set", "name", "value", "build"},
+			want: Invocation{JustFlags: []string{"--set", "name", "value"}, Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "just flag after the target",
+			args: []string{"build", "--dry-run", "x"},
+			want: Invocation{JustFlags: []string{"--dry-run"}, Calls: []justfile.Call{{Target: "build", Args: []string{"x"}}}},
+		},
+		{
+			name: "just short flag after the target goes to the recipe",
+			args: []string{"build", "-n"},
+			want: Invocation{Calls: []justfile.Call{{Target: "build", Args: []string{"-n"}}}},
+		},
+		{
+			name: "@path before the target",
+			args: []string{"@api", "build"},
+			want: Invocation{RepoPath: "@api", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "@path after the target",
+			args: []string{"build", "@api", "x"},
+			want: Invocation{RepoPath: "@api", Calls: []justfile.Call{{Target: "build", Args: []string{"x"}}}},
+		},
+		{
+			name: "qualified target",
+			args: []string{"services/api:build"},
+			want: Invocation{RepoPath: "@services/api", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "qualified target in the root",
+			args: []string{":build"},
+			want: Invocation{RepoPath: "@", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "qualified target with an escaped colon",
+			args: []string{`a\:b:build`},
+			want: Invocation{RepoPath: "@a:b", Calls: []justfile.Call{{Target: "build"}}},
+		},
+		{
+			name: "alias qualified target",
+			args: []string{"api::build", "x"},
+			want: Invocation{RepoPath: "@api", Alias: true, Calls: []justfile.Call{{Target: "build", Args: []string{"x"}}}},
+		},
+		{
+			name: "qualified targets in the same directory",
+			args: []string{"api:build", "+", "api:test"},
+			want: Invocation{RepoPath: "@api", Calls: []justfile.Call{{Target: "build"}, {Target: "test"}}},
+		},
+		{
+			name:    "subcommand name",
+			args:    []string{"-q", "run", "build"},
+			command: "run",
+			want:    Invocation{Quiet: true, Calls: []justfile.Call{{Target: "build"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommand(tt.args, tt.command)
+			if err != nil {
+				t.Fatalf("ParseCommand(%q) failed: %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseCommand(%q) = %+v, want %+v", tt.args, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		noTarget bool
+		contains string
+	}{
+		{name: "empty", args: nil, noTarget: true},
+		{name: "flags only", args: []string{"-q", "--pick"}, noTarget: true},
+		{name: "fzf", args: []string{"--pick", "--fzf", "--print"}, noTarget: true},
+		{name: "@path only", args: []string{"@api"}, noTarget: true},
+		{name: "double dash first", args: []string{"--", "build"}, noTarget: true},
+		{name: "trailing plus", args: []string{"build", "+"}, noTarget: true},
+		{name: "double plus", args: []string{"build", "+", "+", "test"}, noTarget: true},
+		{name: "qualified without a recipe", args: []string{"api:"}, noTarget: true},
+		{name: "unknown flag", args: []string{"--bogus", "build"}, contains: "unknown flag --bogus"},
+		{name: "unknown flag in a group", args: []string{"-qx", "build"}, contains: "unknown flag -x in -qx"},
+		{name: "directory without a value", args: []string{"-d"}, contains: "needs a directory"},
+		{name: "value for a boolean flag", args: []string{"--quiet=yes", "build"}, contains: "doesn't take a value"},
+		{name: "two @paths", args: []string{"@a", "build", "@b"}, contains: "more than one @path"},
+		{name: "@path and a qualified target", args: []string{"@a", "b:build"}, contains: "more than one @path"},
+		{name: "alias and directory of the same name", args: []string{"a::build", "+", "a:test"}, contains: "more than one @path"},
+		{name: "flag after plus", args: []string{"build", "+", "-q", "test"}, contains: "expected a target"},
+		{name: "just flag without its values", args: []string{"build", "--set", "x"}, contains: "needs 2 value(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.args)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want an error", tt.args)
+			}
+			if got := errors.Is(err, ErrNoTarget); got != tt.noTarget {
+				t.Errorf("Parse(%q) = %v, errors.Is(err, ErrNoTarget) = %v, want %v", tt.args, err, got, tt.noTarget)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Parse(%q) = %v, want an error containing %q", tt.args, err, tt.contains)
+			}
+		})
+	}
+}
+
+func TestQualifyRoundTrip(t *testing.T) {
+	for _, relPath := range []string{".", "api", "services/api", "a:b", "ends:", `back\slash`, `a\:b`, "with space"} {
+		word := Qualify(relPath, "build")
+		repoPath, target, alias, ok := SplitQualified(word)
+		want := "@" + relPath
+		if relPath == "." {
+			want = "@"
+		}
+		if !ok || alias || repoPath != want || target != "build" {
+			t.Errorf("SplitQualified(Qualify(%q, build)) = %q, %q, %v, %v, want %q, build, false, true", relPath, repoPath, target, alias, ok, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package completion
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/argv"
+	"github.com/sleexyz/j/internal/justfile"
+	"github.com/sleexyz/j/internal/repo"
+)
+
+// CompleteArgs completes the words after the target: the @path if there isn't
+// one yet, and values for the recipe's next parameter
+func CompleteArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	inv, err := argv.Parse(args)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var paths []string
+	pathDirective := cobra.ShellCompDirectiveNoFileComp
+	if inv.RepoPath == "" && (toComplete == "" || strings.HasPrefix(toComplete, "@")) {
+		paths, pathDirective = CompletePathsWithTarget(cmd, args, toComplete, inv.Calls[0].Target)
+		if toComplete != "" {
+			return paths, pathDirective
+		}
+	}
+
+	justfilePath, err := lookupJustfile(cmd, inv.RepoPath, inv.Alias)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return paths, pathDirective
+	}
+
+	// Split the words the way running the command line would, so the last
+	// call is the recipe whose argument is being completed: in `j a x b`,
+	// b is a recipe of its own if a only takes one argument
+	calls, err := justfile.PlanCalls(justfilePath, inv.Calls)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return paths, pathDirective
+	}
+	call := calls[len(calls)-1]
+	target, err := justfile.LookupTarget(justfilePath, call.Target)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return paths, pathDirective
+	}
+
+	var completions []string
+	directive := cobra.ShellCompDirectiveNoFileComp
+	if max := justfile.MaxArgs(target); max >= 0 && len(call.Args) >= max {
+		// Every parameter has a value, so just takes the next word as a recipe
+		completions = completeRecipes(justfilePath, toComplete)
+	} else {
+		completions, directive = completeParam(target, len(call.Args), toComplete)
+	}
+
+	// Keep the @paths in history order after the parameter's values
+	return append(completions, paths...), directive | pathDirective&cobra.ShellCompDirectiveKeepOrder
+}
+
+// lookupJustfile finds the justfile the command line being completed would
+// run its recipes from. repoPath is an alias if alias is set.
+func lookupJustfile(cmd *cobra.Command, repoPath string, alias bool) (string, error) {
+	root, err := repo.DetectRepoRoot()
+	if err != nil && (repoPath != "" || !errors.Is(err, repo.ErrNoRepoRoot)) {
+		return "", err
+	}
+
+	var justfilePath string
+	directory := ""
+	if flag := cmd.Flags().Lookup("directory"); flag != nil {
+		directory = flag.Value.String()
+	}
+
+	switch {
+	case repoPath != "":
+		lookup := root.Lookup
+		if alias {
+			lookup = root.LookupAlias
+		}
+		resolution, err := lookup(repoPath)
+		if err != nil {
+			return "", err
+		}
+		justfilePath, err = justfile.FindJustfile(resolution.Path)
+		if err != nil {
+			return "", err
+		}
+	case directory != "":
+		justfilePath, err = justfile.FindJustfile(directory)
+		if err != nil {
+			return "", err
+		}
+	default:
+		var rootPath string
+		if root != nil {
+			rootPath = root.Path
+		}
+		justfilePath, err = justfile.FindBestJustfile(rootPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return justfilePath, nil
+}
+
+// completeRecipes completes the names of the recipes in the justfile at
+// justfilePath, described by their doc comments
+func completeRecipes(justfilePath, toComplete string) []string {
+	targets, err := justfile.GetTargets(justfilePath)
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, target := range targets {
+		if strings.HasPrefix(target.Name, toComplete) {
+			completions = append(completions, target.Name+"\t"+describeTarget(target))
+		}
+	}
+	return completions
+}
+
+// completeParam completes the value of target's parameter at index, using
+// the parameter's name and default as descriptions. The last parameter takes
+// every remaining argument if it's variadic.
+func completeParam(target justfile.Target, index int, toComplete string) ([]string, cobra.ShellCompDirective) {
+	param := target.Params[min(index, len(target.Params)-1)]
+
+	var completions []string
+	directive := cobra.ShellCompDirectiveNoFileComp
+
+	if choices := target.Choices(param.Name); len(choices) > 0 {
+		for _, choice := range choices {
+			if strings.HasPrefix(choice, toComplete) {
+				completions = append(completions, choice+"\t"+param.Name)
+			}
+		}
+	} else if target.IsPath(param.Name) {
+		completions, directive = completeFiles(filepath.Dir(target.JustfilePath), toComplete)
+		for i := range completions {
+			completions[i] += "\t" + param.Name
+		}
+	} else if param.HasDefault && strings.HasPrefix(param.Default, toComplete) {
+		completions = append(completions, param.Default+"\t"+param.Name+" (default)")
+	}
+
+	return cobra.AppendActiveHelp(completions, fmt.Sprintf("%s %s", target.Name, param)), directive
+}
+
+// completeFiles completes file paths relative to dir rather than the
+// shell's working directory, since recipes run in their justfile's directory
+func completeFiles(dir, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix, base := "", toComplete
+	if i := strings.LastIndex(toComplete, "/"); i >= 0 {
+		prefix, base = toComplete[:i+1], toComplete[i+1:]
+	}
+
+	listDir := filepath.Join(dir, prefix)
+	if filepath.IsAbs(prefix) {
+		listDir = prefix
+	}
+	entries, err := os.ReadDir(listDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		completions = append(completions, prefix+name)
+	}
+
+	// Keep completing into a directory rather than ending the word
+	if len(completions) == 1 && strings.HasSuffix(completions[0], "/") {
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
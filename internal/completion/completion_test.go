@@ -0,0 +1,157 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sleexyz/j/internal/argv"
+	"github.com/sleexyz/j/internal/justfile"
+	"github.com/sleexyz/j/internal/repo"
+	"github.com/sleexyz/j/internal/testrepo"
+	"github.com/spf13/cobra"
+)
+
+// setupRepo creates a repository with a justfile in each of dirs (relative
+// to the root, "." for the root itself) defining build and a recipe unique to
+// it, and makes it the current directory
+func setupRepo(t *testing.T, dirs []string) string {
+	t.Helper()
+	files := make(map[string]string)
+	for i, dir := range dirs {
+		files[dir+"/justfile"] = "build:\n  echo build\n\nonly" + string(rune('a'+i)) + ":\n  echo only\n"
+	}
+	root := testrepo.New(t, files)
+	testrepo.Chdir(t, root)
+	return root
+}
+
+// resolveWords runs the words of a command line through the argument parser
+// and returns the justfile and recipe the first call would run
+func resolveWords(t *testing.T, root string, words []string) (string, string) {
+	t.Helper()
+	inv, err := argv.Parse(words)
+	if err != nil {
+		t.Fatalf("completed command line %q doesn't parse: %v", words, err)
+	}
+	dir := root
+	if inv.RepoPath != "" {
+		r, err := repo.DetectRepoRoot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		lookup := r.Lookup
+		if inv.Alias {
+			lookup = r.LookupAlias
+		}
+		resolution, err := lookup(inv.RepoPath)
+		if err != nil {
+			t.Fatalf("completed command line %q: %v", words, err)
+		}
+		dir = resolution.Path
+	}
+	justfilePath, err := justfile.FindJustfile(dir)
+	if err != nil {
+		t.Fatalf("completed command line %q: %v", words, err)
+	}
+	return justfilePath, inv.Calls[0].Target
+}
+
+func values(completions []string) []string {
+	var values []string
+	for _, completion := range completions {
+		value, _, _ := strings.Cut(completion, "\t")
+		values = append(values, value)
+	}
+	return values
+}
+
+// TestCompletionsRoundTrip checks that every word completion offers parses
+// back to the recipe it was offered for, including in directories whose
+// names need escaping. The shells quote the words themselves.
+func TestCompletionsRoundTrip(t *testing.T) {
+	dirs := []string{".", "plain", "with space", "co:lon", "co:lon/deeper", `back\slash`, "quote'd", "dollar$x", "ends:"}
+	root := setupRepo(t, dirs)
+	// An alias shadowed by a directory of the same name
+	config := `{"aliases": {"sp": "with space", "plain": "co:lon"}}`
+	if err := os.WriteFile(filepath.Join(root, ".j.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	qualified := make(map[string]bool)
+	aliased := make(map[string]bool)
+	for _, toComplete := range []string{"", ":", "co", `co\:`, "w", "only", "sp::", "plain::"} {
+		completions, _ := CompleteTargets(cmd, nil, toComplete)
+		for _, value := range values(completions) {
+			if value == "" {
+				t.Errorf("CompleteTargets(%q) offered an empty completion", toComplete)
+				continue
+			}
+			if !strings.Contains(value, ":") {
+				// A bare name runs from the current directory's justfile or
+				// is followed by an @path: `j onlyb @<TAB>`
+				justfilePath, target := resolveWords(t, root, []string{value})
+				if _, err := justfile.LookupTarget(justfilePath, target); err != nil {
+					checkPaths(t, cmd, root, value)
+				}
+				continue
+			}
+			justfilePath, target := resolveWords(t, root, []string{value})
+			if _, err := justfile.LookupTarget(justfilePath, target); err != nil {
+				t.Errorf("completion %q doesn't resolve: %v", value, err)
+			}
+			if strings.Contains(value, "::") {
+				aliased[value] = true
+			}
+			qualified[filepath.Dir(justfilePath)] = true
+		}
+	}
+	for alias, dir := range map[string]string{"sp": "with space", "plain": "co:lon"} {
+		word := alias + "::build"
+		if !aliased[word] {
+			t.Errorf("%s wasn't offered", word)
+			continue
+		}
+		justfilePath, _ := resolveWords(t, root, []string{word})
+		if want := filepath.Join(root, dir, "justfile"); justfilePath != want {
+			t.Errorf("%s resolved to %s, want %s", word, justfilePath, want)
+		}
+	}
+	for _, dir := range dirs {
+		if !qualified[filepath.Join(root, dir)] {
+			t.Errorf("no qualified completion resolved to %q", dir)
+		}
+	}
+
+	// `j build @<TAB>` offers every directory
+	seen := checkPaths(t, cmd, root, "build")
+	for _, dir := range dirs[1:] {
+		if !seen[filepath.Join(root, dir)] {
+			t.Errorf("no @path completion for build resolved to %q", dir)
+		}
+	}
+}
+
+// checkPaths checks that each @path completion for `j <target> @` runs
+// target, returning the directories they resolved to
+func checkPaths(t *testing.T, cmd *cobra.Command, root, target string) map[string]bool {
+	t.Helper()
+	seen := make(map[string]bool)
+	completions, _ := CompletePathsWithTarget(cmd, []string{target}, "@", target)
+	for _, value := range values(completions) {
+		justfilePath, _ := resolveWords(t, root, []string{target, value})
+		if _, err := justfile.LookupTarget(justfilePath, target); err != nil {
+			t.Errorf("completion %q for %s doesn't resolve: %v", value, target, err)
+		}
+		seen[filepath.Dir(justfilePath)] = true
+	}
+	if len(seen) == 0 {
+		t.Errorf("no @path completions for %s", target)
+	}
+	return seen
+}
@@ -1,160 +1,260 @@
 package completion
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/config"
+	"github.com/sleexyz/j/internal/fuzzy"
+	"github.com/sleexyz/j/internal/history"
 	"github.com/sleexyz/j/internal/justfile"
 	"github.com/sleexyz/j/internal/repo"
 )
 
 // CompleteRepoPaths provides completion for @path arguments
 func CompleteRepoPaths(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	repoRoot, err := repo.FindRepoRoot()
+	return completePaths(cmd, toComplete, "")
+}
+
+// CompletePathsWithTarget provides completion for @path arguments filtered by target
+func CompletePathsWithTarget(cmd *cobra.Command, args []string, toComplete string, target string) ([]string, cobra.ShellCompDirective) {
+	return completePaths(cmd, toComplete, target)
+}
+
+// completePaths completes the directories of justfiles that define target
+// (or all of them if it's "") and their aliases. Each is described by its
+// number of recipes and the directory's description, and ranked by how
+// often target, or anything, was run there.
+func completePaths(cmd *cobra.Command, toComplete, target string) ([]string, cobra.ShellCompDirective) {
+	root, err := repo.DetectRepoRoot()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	style := newPathStyle(root, toComplete)
+	repoRoot := style.root
+
+	files, err := justfile.ParseAll(cmd.Context(), repoRoot)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
 
-	// Remove @ prefix from toComplete if present
-	searchPath := toComplete
-	if strings.HasPrefix(searchPath, "@") {
-		searchPath = strings.TrimPrefix(searchPath, "@")
+	aliasNames := repo.AliasNames(repo.AliasesFromFiles(repoRoot, files))
+	var descriptions map[string]string
+	if cfg, err := config.Load(repoRoot); err == nil {
+		descriptions = cfg.Descriptions
 	}
 
-	// Find directories with justfiles
-	var allPaths []string
+	frecency := loadFrecency(repoRoot)
 
-	// Walk the directory tree looking for justfiles
-	err = filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip errors, continue walking
+	var candidates []candidate
+	for _, file := range files {
+		logDiagnostics(file.Diagnostics)
+
+		if target != "" && !containsTarget(file.Targets, target) {
+			continue
 		}
 
-		// Only look at directories
-		if !info.IsDir() {
-			return nil
+		dir := filepath.Dir(file.Path)
+		relPath, err := filepath.Rel(repoRoot, dir)
+		if err != nil {
+			continue
 		}
+		relPath = filepath.ToSlash(relPath)
 
-		// Skip hidden directories and common build/cache directories
-		baseName := filepath.Base(path)
-		if strings.HasPrefix(baseName, ".") || 
-		   baseName == "node_modules" || 
-		   baseName == "target" || 
-		   baseName == "dist" || 
-		   baseName == "build" ||
-		   baseName == ".cache" ||
-		   baseName == ".tmp" ||
-		   baseName == "tmp" ||
-		   baseName == "vendor" ||
-		   baseName == ".venv" ||
-		   baseName == "venv" ||
-		   baseName == "__pycache__" ||
-		   baseName == ".pytest_cache" ||
-		   baseName == "coverage" ||
-		   baseName == ".nyc_output" ||
-		   baseName == "logs" {
-			return filepath.SkipDir
+		description := file.Description
+		if configured, ok := descriptions[relPath]; ok {
+			description = configured
+		}
+		summary := describe(recipeCount(len(file.Targets)), description)
+		score := frecency.Dir(dir)
+		if target != "" {
+			score = frecency.Target(file.Path, target)
 		}
 
-		// Check if this directory has a justfile
-		justfilePath := filepath.Join(path, "justfile")
-		if _, err := os.Stat(justfilePath); err == nil {
-			// Convert absolute path to relative path from repo root
-			relPath, err := filepath.Rel(repoRoot, path)
-			if err != nil {
-				return nil
-			}
+		if repoPath, ok := style.format(dir); ok {
+			candidates = append(candidates, candidate{Value: repoPath, Description: summary, Frecency: score})
+		}
 
-			// Skip the root directory itself
-			if relPath == "." {
-				return nil
+		// Offer aliases too, unless a file or directory of the same name
+		// shadows them
+		if !style.relative() {
+			for _, name := range aliasNames[relPath] {
+				if _, err := os.Lstat(filepath.Join(style.root, name)); err == nil {
+					continue
+				}
+				candidates = append(candidates, candidate{
+					Value:       style.prefix + name,
+					Description: describe("@"+relPath, summary),
+					Frecency:    score,
+				})
 			}
-
-			repoPath := "@" + relPath
-			allPaths = append(allPaths, repoPath)
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
 	}
 
-	// Use fuzzy matching instead of prefix matching
-	completions := FuzzyMatchStrings(toComplete, allPaths)
-
-	return completions, cobra.ShellCompDirectiveNoFileComp
+	return matchCandidates(toComplete, candidates)
 }
 
-// CompletePathsWithTarget provides completion for @path arguments filtered by target
-func CompletePathsWithTarget(cmd *cobra.Command, args []string, toComplete string, target string) ([]string, cobra.ShellCompDirective) {
-	repoRoot, err := repo.FindRepoRoot()
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
+// recipeCount formats a number of recipes for descriptions
+func recipeCount(n int) string {
+	if n == 1 {
+		return "1 recipe"
 	}
+	return fmt.Sprintf("%d recipes", n)
+}
 
-	// Remove @ prefix from toComplete if present
-	searchPath := toComplete
-	if strings.HasPrefix(searchPath, "@") {
-		searchPath = strings.TrimPrefix(searchPath, "@")
-	}
+// pathStyle describes how completions for the @path being typed are written
+type pathStyle struct {
+	// root is the directory to search for justfiles
+	root string
+	// prefix starts root-relative completions: @@ and @/ mean the innermost
+	// repository, @ the outermost
+	prefix string
+	// cwd is set when completing @./ and @../ paths, which are relative to it
+	cwd string
+}
 
-	// Find all justfiles and check which ones contain the target
-	var allPaths []string
-	
-	justfiles, err := justfile.FindAllJustfiles(repoRoot)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
+func newPathStyle(root *repo.Root, toComplete string) pathStyle {
+	if repo.IsRelative(toComplete) {
+		if cwd, err := os.Getwd(); err == nil {
+			return pathStyle{root: root.Path, prefix: "@", cwd: cwd}
+		}
 	}
-	
-	for _, justfilePath := range justfiles {
-		// Check if the justfile contains the target
-		hasTarget, err := justfileContainsTarget(justfilePath, target)
-		if err != nil || !hasTarget {
-			continue
+	for _, prefix := range []string{"@@", "@/"} {
+		if strings.HasPrefix(toComplete, prefix) {
+			return pathStyle{root: root.Inner, prefix: prefix}
 		}
-		
-		// Convert absolute path to @path format
-		dir := filepath.Dir(justfilePath)
-		relPath, err := filepath.Rel(repoRoot, dir)
+	}
+	return pathStyle{root: root.Path, prefix: "@"}
+}
+
+// relative reports whether completions are relative to the current directory
+func (s pathStyle) relative() bool {
+	return s.cwd != ""
+}
+
+// format renders dir as an @path in this style, or reports false if it
+// shouldn't be offered
+func (s pathStyle) format(dir string) (string, bool) {
+	if s.relative() {
+		relPath, err := filepath.Rel(s.cwd, dir)
 		if err != nil {
-			continue
+			return "", false
 		}
-		
-		if relPath == "." {
-			// Skip root directory since we use @path syntax
+		relPath = filepath.ToSlash(relPath)
+		if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") {
+			return "@" + relPath, true
+		}
+		return "@./" + relPath, true
+	}
+
+	relPath, err := filepath.Rel(s.root, dir)
+	if err != nil || relPath == "." {
+		// Skip the root directory itself
+		return "", false
+	}
+	return s.prefix + filepath.ToSlash(relPath), true
+}
+
+// candidate is a completion and its description
+type candidate struct {
+	Value       string
+	Description string
+	// Frecency ranks candidates that have been run often and recently first
+	Frecency float64
+}
+
+// matchCandidates fuzzy matches candidates against toComplete by value and
+// formats them as cobra completions with descriptions, best first. Repeated
+// values, e.g. an alias with the same name as a directory, are offered once.
+// When history ranks the candidates, the directive asks the shell to keep
+// their order.
+func matchCandidates(toComplete string, candidates []candidate) ([]string, cobra.ShellCompDirective) {
+	var values []string
+	seen := make(map[string]candidate)
+	boosted := false
+	for _, c := range candidates {
+		if _, ok := seen[c.Value]; ok {
 			continue
 		}
-		
-		repoPath := "@" + relPath
-		allPaths = append(allPaths, repoPath)
+		values = append(values, c.Value)
+		seen[c.Value] = c
+		boosted = boosted || c.Frecency > 0
 	}
 
 	// Use fuzzy matching instead of prefix matching
-	completions := FuzzyMatchStrings(toComplete, allPaths)
-	
-	return completions, cobra.ShellCompDirectiveNoFileComp
+	matched := fuzzy.MatchStrings(toComplete, values)
+
+	directive := cobra.ShellCompDirectiveNoFileComp
+	if boosted {
+		rank := func(value string) int {
+			score := history.Boost(seen[value].Frecency)
+			if toComplete != "" {
+				score += fuzzy.Score(toComplete, value)
+			}
+			return score
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			return rank(matched[i]) > rank(matched[j])
+		})
+		directive |= cobra.ShellCompDirectiveKeepOrder
+	}
+
+	completions := make([]string, len(matched))
+	for i, value := range matched {
+		completions[i] = value
+		if description := seen[value].Description; description != "" {
+			completions[i] += "\t" + description
+		}
+	}
+	return completions, directive
 }
 
-// justfileContainsTarget checks if a justfile contains the specified target
-func justfileContainsTarget(justfilePath, target string) (bool, error) {
-	targets, err := justfile.GetTargets(justfilePath)
+// loadFrecency scores recipes and directories by the run history, or
+// returns nil if history is turned off or can't be read
+func loadFrecency(repoRoot string) *history.Frecency {
+	if cfg, err := config.Load(repoRoot); err != nil || cfg.DisableHistory {
+		return nil
+	}
+	entries, err := history.Load()
 	if err != nil {
-		// Fallback to file parsing
-		targets, err = justfile.GetTargetsFromFile(justfilePath)
-		if err != nil {
-			return false, err
+		cobra.CompDebugln("history: "+err.Error(), false)
+		return nil
+	}
+	return history.NewFrecency(entries, time.Now())
+}
+
+// describe joins the non-empty parts of a description, flattening any
+// whitespace that would break the completion protocol
+func describe(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			kept = append(kept, part)
 		}
 	}
+	return strings.Join(kept, " · ")
+}
 
+// logDiagnostics reports justfile problems to cobra's completion debug log,
+// since anything written to stdout or stderr would corrupt the completions
+func logDiagnostics(diagnostics []justfile.Diagnostic) {
+	for _, d := range diagnostics {
+		cobra.CompDebugln("warning: "+d.Error(), false)
+	}
+}
+
+// containsTarget checks if targets contains the specified target name
+func containsTarget(targets []justfile.Target, target string) bool {
 	for _, t := range targets {
 		if t.Name == target {
-			return true, nil
+			return true
 		}
 	}
 
-	return false, nil
-}
\ No newline at end of file
+	return false
+}
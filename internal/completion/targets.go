@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/sleexyz/j/internal/argv"
 	"github.com/sleexyz/j/internal/justfile"
 	"github.com/sleexyz/j/internal/repo"
 )
@@ -28,16 +29,22 @@ func CompleteTargets(cmd *cobra.Command, args []string, toComplete string) ([]st
 		}
 	}
 
-	repoRoot, err := repo.FindRepoRoot()
+	root, err := repo.DetectRepoRoot()
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
+	repoRoot := root.Path
+
+	// services/api:b and api::b complete qualified targets
+	if repoPath == "" && strings.ContainsAny(toComplete, ":/") {
+		return completeQualified(cmd, repoRoot, toComplete)
+	}
 
 	var targets []justfile.Target
 
 	if repoPath != "" {
 		// If a specific websim path is provided, only get targets from that path
-		resolvedPath, err := repo.ResolveRepoPath(repoPath, repoRoot)
+		resolvedPath, err := root.Resolve(repoPath)
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveError
 		}
@@ -56,10 +63,12 @@ func CompleteTargets(cmd *cobra.Command, args []string, toComplete string) ([]st
 		}
 	} else {
 		// Get targets from all justfiles in the repository
-		targets, err = justfile.GetTargetsFromAllJustfiles(repoRoot)
+		var diagnostics []justfile.Diagnostic
+		targets, diagnostics, err = justfile.GetTargetsAndDiagnostics(cmd.Context(), repoRoot)
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveError
 		}
+		logDiagnostics(diagnostics)
 	}
 
 	// Group targets by name to detect duplicates
@@ -68,36 +77,90 @@ func CompleteTargets(cmd *cobra.Command, args []string, toComplete string) ([]st
 		targetMap[target.Name] = append(targetMap[target.Name], target)
 	}
 
-	var completions []string
+	// The justfile a bare target name runs from here
+	best, _ := justfile.FindBestJustfile(repoRoot)
+	frecency := loadFrecency(repoRoot)
+
+	var candidates []candidate
 	
 	// For each unique target name
 	for targetName, targetList := range targetMap {
 		if len(targetList) == 1 {
 			// Single target - just show the target name
-			completions = append(completions, targetName)
+			target := targetList[0]
+			candidates = append(candidates, candidate{
+				Value:       targetName,
+				Description: describeTarget(target),
+				Frecency:    frecency.Target(target.JustfilePath, targetName),
+			})
 		} else {
-			// Multiple targets with same name - show "target (path)" format
+			// Multiple targets with same name - offer the bare name for the one
+			// it runs from here, and each one qualified like services/api:build
+			// so the inserted word runs exactly that recipe
 			for _, target := range targetList {
-				// Convert absolute path to @path format
 				relPath, err := filepath.Rel(repoRoot, filepath.Dir(target.JustfilePath))
 				if err != nil {
 					continue
 				}
-				if relPath == "." {
-					// Root directory - just show target name
-					completions = append(completions, targetName)
-					continue
+				relPath = filepath.ToSlash(relPath)
+				description := describe(displayRepoPath(relPath), describeTarget(target))
+				score := frecency.Target(target.JustfilePath, targetName)
+				if target.JustfilePath == best {
+					candidates = append(candidates, candidate{Value: targetName, Description: description, Frecency: score})
 				}
-				repoPath := "@" + relPath
-				// Show as "target (path)" so fuzzy matching can find both target name and path
-				completion := targetName + " (" + repoPath + ")"
-				completions = append(completions, completion)
+				candidates = append(candidates, candidate{Value: argv.Qualify(relPath, targetName), Description: description, Frecency: score})
 			}
 		}
 	}
 
-	// Use fuzzy matching instead of prefix matching
-	filtered := FuzzyMatchStrings(toComplete, completions)
+	return matchCandidates(toComplete, candidates)
+}
+
+// completeQualified offers every recipe in the repository qualified by its
+// directory, like services/api:build, and by each of its directory's
+// aliases, like api::build
+func completeQualified(cmd *cobra.Command, repoRoot, toComplete string) ([]string, cobra.ShellCompDirective) {
+	files, err := justfile.ParseAll(cmd.Context(), repoRoot)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	aliasNames := repo.AliasNames(repo.AliasesFromFiles(repoRoot, files))
+	frecency := loadFrecency(repoRoot)
+
+	var candidates []candidate
+	for _, file := range files {
+		logDiagnostics(file.Diagnostics)
+
+		relPath, err := filepath.Rel(repoRoot, filepath.Dir(file.Path))
+		if err != nil {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		for _, target := range file.Targets {
+			description := describe(displayRepoPath(relPath), describeTarget(target))
+			score := frecency.Target(file.Path, target.Name)
+			candidates = append(candidates, candidate{Value: argv.Qualify(relPath, target.Name), Description: description, Frecency: score})
+			for _, name := range aliasNames[relPath] {
+				candidates = append(candidates, candidate{Value: argv.QualifyAlias(name, target.Name), Description: description, Frecency: score})
+			}
+		}
+	}
+
+	return matchCandidates(toComplete, candidates)
+}
+
+// displayRepoPath formats a directory relative to the repo root for
+// descriptions
+func displayRepoPath(relPath string) string {
+	if relPath == "." {
+		return "@ (repo root)"
+	}
+	return "@" + relPath
+}
 
-	return filtered, cobra.ShellCompDirectiveNoFileComp
+// describeTarget describes a recipe by its doc comment and parameters, like
+// "Deploy the app · <env> [region=us-east-1]"
+func describeTarget(target justfile.Target) string {
+	return describe(target.Description, target.Usage())
 }
\ No newline at end of file
@@ -0,0 +1,187 @@
+// Package config loads j's settings: the user config file, then the repo's
+// .j.json layered on top of it. Lists are appended, maps are merged key by
+// key, switches are on if either file turns them on, and nested_roots from
+// the repo config wins.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// RepoFile is the name of the per-repository config file at the repo root
+const RepoFile = ".j.json"
+
+// Config holds settings read from the user and repository config files
+type Config struct {
+	// Ignore lists extra directory names to skip when searching for justfiles
+	Ignore []string `json:"ignore,omitempty"`
+	// RootMarkers are file names that mark a repository root. Only read from
+	// the user config, since the repo config can't be found before the root.
+	RootMarkers []string `json:"root_markers,omitempty"`
+	// NestedRoots picks which root @paths resolve against inside a git
+	// submodule: "outermost" (the superproject, default) or "innermost"
+	NestedRoots string `json:"nested_roots,omitempty"`
+	// Aliases maps names usable as @name to directories relative to the
+	// repo root, e.g. {"api": "services/backend/api"}
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Descriptions describe directories, keyed by their path relative to
+	// the repo root, for @path completion. They override a justfile's
+	// header comment.
+	Descriptions map[string]string `json:"descriptions,omitempty"`
+	// DisableHistory stops j from recording runs, and completion from
+	// ranking by them. Runs are recorded verbatim, recipe arguments and
+	// --set values included, so turn it off if those carry secrets.
+	DisableHistory bool `json:"disable_history,omitempty"`
+	// Picker makes `j` with no arguments in a terminal open the interactive
+	// picker instead of showing help
+	Picker bool `json:"picker,omitempty"`
+}
+
+// DefaultRootMarkers is used when the user config sets no root markers
+var DefaultRootMarkers = []string{".j-root"}
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]*Config)
+)
+
+// UserPath returns the location of the user config file
+func UserPath() string {
+	if path := os.Getenv("J_CONFIG"); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "j", "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "j", "config.json")
+}
+
+// RepoPath returns the location of the config file for the repo at repoRoot
+func RepoPath(repoRoot string) string {
+	return filepath.Join(repoRoot, RepoFile)
+}
+
+// Paths returns the config files that apply to repoRoot, in merge order
+func Paths(repoRoot string) []string {
+	var paths []string
+	if path := UserPath(); path != "" {
+		paths = append(paths, path)
+	}
+	if repoRoot != "" {
+		paths = append(paths, RepoPath(repoRoot))
+	}
+	return paths
+}
+
+// LoadFile reads a single config file. A missing file is an empty config.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Load returns the user config merged with the config of the repo at repoRoot.
+// An empty repoRoot loads only the user config. Results are cached for the
+// lifetime of the process.
+func Load(repoRoot string) (*Config, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if cfg, ok := cache[repoRoot]; ok {
+		return cfg, nil
+	}
+
+	merged := &Config{}
+	for _, path := range Paths(repoRoot) {
+		cfg, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		merged.merge(cfg)
+	}
+
+	cache[repoRoot] = merged
+	return merged, nil
+}
+
+// merge layers other on top of c
+func (c *Config) merge(other *Config) {
+	c.Ignore = append(c.Ignore, other.Ignore...)
+	c.RootMarkers = append(c.RootMarkers, other.RootMarkers...)
+	if other.NestedRoots != "" {
+		c.NestedRoots = other.NestedRoots
+	}
+	c.DisableHistory = c.DisableHistory || other.DisableHistory
+	c.Picker = c.Picker || other.Picker
+	for name, path := range other.Aliases {
+		if c.Aliases == nil {
+			c.Aliases = make(map[string]string)
+		}
+		c.Aliases[name] = path
+	}
+	for path, description := range other.Descriptions {
+		if c.Descriptions == nil {
+			c.Descriptions = make(map[string]string)
+		}
+		c.Descriptions[path] = description
+	}
+}
+
+// Validate reports settings with invalid values
+func (c *Config) Validate() error {
+	switch c.NestedRoots {
+	case "", "outermost", "innermost":
+	default:
+		return fmt.Errorf("nested_roots must be \"outermost\" or \"innermost\", got %q", c.NestedRoots)
+	}
+	for name, path := range c.Aliases {
+		if err := ValidateAlias(name); err != nil {
+			return err
+		}
+		if path == "" || filepath.IsAbs(path) {
+			return fmt.Errorf("alias %q must point to a path relative to the repo root, got %q", name, path)
+		}
+	}
+	for path := range c.Descriptions {
+		if path == "" || filepath.IsAbs(path) {
+			return fmt.Errorf("descriptions must be keyed by paths relative to the repo root, got %q", path)
+		}
+	}
+	return nil
+}
+
+// ValidateAlias reports whether name can be used as an @name path alias
+func ValidateAlias(name string) error {
+	if name == "" || strings.ContainsAny(name, "/\\@: \t") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid alias name %q: must be non-empty, not start with '.', and not contain '/', '@', ':' or spaces", name)
+	}
+	return nil
+}
@@ -1,29 +1,29 @@
-package completion
+package fuzzy
 
 import (
 	"sort"
 	"strings"
 )
 
-// FuzzyMatch represents a fuzzy match with its score
-type FuzzyMatch struct {
+// Match represents a fuzzy match with its score
+type Match struct {
 	Value string
 	Score int
 }
 
-// FuzzyMatchStrings performs fuzzy matching on a slice of strings
+// MatchStrings performs fuzzy matching on a slice of strings
 // Returns matches sorted by score (best matches first)
-func FuzzyMatchStrings(input string, candidates []string) []string {
+func MatchStrings(input string, candidates []string) []string {
 	if input == "" {
 		return candidates
 	}
 	
-	var matches []FuzzyMatch
+	var matches []Match
 	
 	for _, candidate := range candidates {
-		score := fuzzyScore(input, candidate)
+		score := Score(input, candidate)
 		if score > 0 {
-			matches = append(matches, FuzzyMatch{
+			matches = append(matches, Match{
 				Value: candidate,
 				Score: score,
 			})
@@ -44,9 +44,9 @@ func FuzzyMatchStrings(input string, candidates []string) []string {
 	return result
 }
 
-// fuzzyScore calculates a fuzzy match score between input and candidate
+// Score calculates a fuzzy match score between input and candidate
 // Returns 0 if no match, higher scores for better matches
-func fuzzyScore(input, candidate string) int {
+func Score(input, candidate string) int {
 	input = strings.ToLower(input)
 	candidate = strings.ToLower(candidate)
 	
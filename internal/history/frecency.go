@@ -0,0 +1,73 @@
+package history
+
+import (
+	"time"
+)
+
+// Frecency scores recipes and directories by how often and how recently
+// they were run. A nil *Frecency scores everything 0.
+type Frecency struct {
+	targets map[string]float64
+	dirs    map[string]float64
+}
+
+// NewFrecency scores the runs in entries as of now
+func NewFrecency(entries []Entry, now time.Time) *Frecency {
+	f := &Frecency{
+		targets: make(map[string]float64),
+		dirs:    make(map[string]float64),
+	}
+	for _, entry := range entries {
+		weight := recencyWeight(now.Sub(entry.Time))
+		f.dirs[entry.Dir()] += weight
+		for _, call := range entry.Calls {
+			f.targets[targetKey(entry.JustfilePath, call.Target)] += weight
+		}
+	}
+	return f
+}
+
+// MaxBoost caps Boost, so a frequently run candidate beats other fuzzy
+// matches of the same kind but not an exact or prefix match
+const MaxBoost = 200
+
+// Boost converts a frecency score into a bonus to add to a fuzzy match score
+func Boost(frecency float64) int {
+	return min(int(frecency*10), MaxBoost)
+}
+
+// recencyWeight is what one run that happened age ago counts for
+func recencyWeight(age time.Duration) float64 {
+	switch {
+	case age < time.Hour:
+		return 4
+	case age < 24*time.Hour:
+		return 2
+	case age < 7*24*time.Hour:
+		return 1
+	case age < 30*24*time.Hour:
+		return 0.5
+	default:
+		return 0.25
+	}
+}
+
+// Target scores the recipe target in the justfile at justfilePath
+func (f *Frecency) Target(justfilePath, target string) float64 {
+	if f == nil {
+		return 0
+	}
+	return f.targets[targetKey(justfilePath, target)]
+}
+
+// Dir scores the directory dir by the recipes run in it
+func (f *Frecency) Dir(dir string) float64 {
+	if f == nil {
+		return 0
+	}
+	return f.dirs[dir]
+}
+
+func targetKey(justfilePath, target string) string {
+	return justfilePath + "\x00" + target
+}
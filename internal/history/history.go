@@ -0,0 +1,155 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+// MaxSize is how large the history file grows, in bytes, before its oldest
+// runs are dropped to bring it down to half that. Since trimming halves it,
+// recording a run is almost always a single append.
+const MaxSize = 2 << 20
+
+// Entry is one successful run of j, fully resolved so it can be repeated
+// from anywhere
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Cwd is the directory j was run from
+	Cwd      string `json:"cwd"`
+	RepoRoot string `json:"repo_root,omitempty"`
+	// RepoPath is the canonical @path of the justfile's directory, or "" if
+	// there was no repository root
+	RepoPath     string          `json:"repo_path,omitempty"`
+	JustfilePath string          `json:"justfile_path"`
+	JustFlags    []string        `json:"just_flags,omitempty"`
+	Calls        []justfile.Call `json:"calls"`
+}
+
+// Dir returns the directory the entry's recipes ran in
+func (e Entry) Dir() string {
+	return filepath.Dir(e.JustfilePath)
+}
+
+// Path returns the location of the history file
+func Path() string {
+	if path := os.Getenv("J_HISTORY"); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "j", "history.jsonl")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", "j", "history.jsonl")
+}
+
+// Load reads the history, oldest run first. A missing file is an empty
+// history, and lines that can't be read (e.g. from a newer version of j) are
+// skipped.
+func Load() ([]Entry, error) {
+	path := Path()
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || len(entry.Calls) == 0 {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Record appends entry to the history, dropping the oldest runs once the
+// file is larger than MaxSize
+func Record(entry Entry) error {
+	path := Path()
+	if path == "" {
+		return errors.New("no location for the history file")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	info, statErr := f.Stat()
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if statErr == nil && info.Size() > MaxSize {
+		return trim(path)
+	}
+	return nil
+}
+
+// trim drops the oldest lines of the history file at path until it's at
+// most half of MaxSize, replacing it atomically
+func trim(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Keep whole lines, even ones Load can't read
+	start := 0
+	for len(data)-start > MaxSize/2 {
+		i := bytes.IndexByte(data[start:], '\n')
+		if i < 0 {
+			start = len(data)
+			break
+		}
+		start += i + 1
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data[start:], 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Clear deletes the history
+func Clear() error {
+	path := Path()
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
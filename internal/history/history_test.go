@@ -0,0 +1,51 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+func TestRecordTrims(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	t.Setenv("J_HISTORY", path)
+
+	var last Entry
+	for i := 0; ; i++ {
+		last = Entry{
+			Time:         time.Unix(int64(i), 0).UTC(),
+			Cwd:          "/repo",
+			JustfilePath: "/repo/justfile",
+			Calls:        []justfile.Call{{Target: "build", Args: []string{strconv.Itoa(i)}}},
+		}
+		before, _ := os.Stat(path)
+		if err := Record(last); err != nil {
+			t.Fatal(err)
+		}
+		after, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if after.Size() > MaxSize {
+			t.Fatalf("history is %d bytes, more than MaxSize", after.Size())
+		}
+		if before != nil && after.Size() < before.Size() {
+			if after.Size() > MaxSize/2 {
+				t.Errorf("trimmed history is %d bytes, more than half of MaxSize", after.Size())
+			}
+			break
+		}
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || !entries[len(entries)-1].Time.Equal(last.Time) {
+		t.Errorf("the newest run was dropped")
+	}
+}
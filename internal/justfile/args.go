@@ -0,0 +1,87 @@
+package justfile
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ArgsError reports recipe arguments that don't fit the recipe's parameters
+type ArgsError struct {
+	Target Target
+	// Got is how many arguments were given
+	Got int
+	// Missing lists required parameters without a value
+	Missing []Param
+}
+
+func (e *ArgsError) Error() string {
+	if len(e.Missing) > 0 {
+		names := make([]string, len(e.Missing))
+		for i, param := range e.Missing {
+			names[i] = param.Name
+		}
+		return fmt.Sprintf("recipe '%s' is missing required argument(s): %s", e.Target.Name, strings.Join(names, ", "))
+	}
+	if MaxArgs(e.Target) == 0 {
+		return fmt.Sprintf("recipe '%s' takes no arguments, got %d", e.Target.Name, e.Got)
+	}
+	return fmt.Sprintf("recipe '%s' takes at most %d argument(s), got %d", e.Target.Name, MaxArgs(e.Target), e.Got)
+}
+
+// CheckArgs checks args against target's parameters: every required
+// parameter needs a value, and only variadic recipes take extra arguments
+func CheckArgs(target Target, args []string) error {
+	if max := MaxArgs(target); max >= 0 && len(args) > max {
+		return &ArgsError{Target: target, Got: len(args)}
+	}
+
+	var missing []Param
+	for i, param := range target.Params {
+		if param.Required() && i >= len(args) {
+			missing = append(missing, param)
+		}
+	}
+	if len(missing) > 0 {
+		return &ArgsError{Target: target, Got: len(args), Missing: missing}
+	}
+	return nil
+}
+
+// Usage formats target's parameters for a usage line, like
+// `<env> [region=us-east-1] [flags...]`
+func (t Target) Usage() string {
+	params := make([]string, len(t.Params))
+	for i, param := range t.Params {
+		params[i] = param.String()
+	}
+	return strings.Join(params, " ")
+}
+
+// ChoicesAnnotation lists the values a parameter accepts. `# j: choices=a,b`
+// applies to the recipe's first parameter, `# j: choices.region=us,eu` to
+// the named one.
+const ChoicesAnnotation = "choices"
+
+// Choices returns the values the named parameter accepts, or nil if any value
+// is allowed
+func (t Target) Choices(param string) []string {
+	value, ok := t.Annotations[ChoicesAnnotation+"."+param]
+	if !ok && len(t.Params) > 0 && t.Params[0].Name == param {
+		value, ok = t.Annotations[ChoicesAnnotation]
+	}
+	if !ok || value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// PathAnnotation names parameters that take file paths relative to the
+// recipe's directory, e.g. `# j: path=file,outdir`
+const PathAnnotation = "path"
+
+// IsPath reports whether the named parameter takes a file path
+func (t Target) IsPath(param string) bool {
+	value, ok := t.Annotations[PathAnnotation]
+	return ok && slices.Contains(strings.Split(value, ","), param)
+}
@@ -1,24 +1,50 @@
 package justfile
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+
+	"github.com/sleexyz/j/internal/config"
 )
 
+// skipDirs are directories never descended into when looking for justfiles
+var skipDirs = map[string]bool{
+	".git":          true,
+	"node_modules":  true,
+	".next":         true,
+	"dist":          true,
+	"build":         true,
+	"target":        true,
+	".cache":        true,
+	".tmp":          true,
+	"tmp":           true,
+	"vendor":        true,
+	".venv":         true,
+	"venv":          true,
+	"__pycache__":   true,
+	".pytest_cache": true,
+	"coverage":      true,
+	".nyc_output":   true,
+	"logs":          true,
+	"*.log":         true,
+}
+
 // FindJustfile searches for a justfile in the specified directory
 func FindJustfile(dir string) (string, error) {
 	justfilePath := filepath.Join(dir, "justfile")
-	
+
 	if _, err := os.Stat(justfilePath); err == nil {
 		return justfilePath, nil
 	}
-	
+
 	return "", fmt.Errorf("no justfile found in %s", dir)
 }
 
 // FindBestJustfile finds the most appropriate justfile for the current context
-// It first checks the current directory, then the repo root
+// It first checks the current directory, then the repo root (if repoRoot is set)
 func FindBestJustfile(repoRoot string) (string, error) {
 	// Try current directory first
 	cwd, err := os.Getwd()
@@ -31,8 +57,10 @@ func FindBestJustfile(repoRoot string) (string, error) {
 	}
 
 	// Try repo root
-	if justfile, err := FindJustfile(repoRoot); err == nil {
-		return justfile, nil
+	if repoRoot != "" {
+		if justfile, err := FindJustfile(repoRoot); err == nil {
+			return justfile, nil
+		}
 	}
 
 	return "", fmt.Errorf("no justfile found in current directory or repo root")
@@ -41,54 +69,57 @@ func FindBestJustfile(repoRoot string) (string, error) {
 // FindAllJustfiles finds all justfiles in the repository, skipping common ignored directories
 func FindAllJustfiles(repoRoot string) ([]string, error) {
 	var justfiles []string
-	
-	// Common directories to skip for performance
-	skipDirs := map[string]bool{
-		".git":         true,
-		"node_modules": true,
-		".next":        true,
-		"dist":         true,
-		"build":        true,
-		"target":       true,
-		".cache":       true,
-		".tmp":         true,
-		"tmp":          true,
-		"vendor":       true,
-		".venv":        true,
-		"venv":         true,
-		"__pycache__":  true,
-		".pytest_cache": true,
-		"coverage":     true,
-		".nyc_output":  true,
-		"logs":         true,
-		"*.log":        true,
+
+	err := walkJustfiles(context.Background(), repoRoot, nil, func(path string) error {
+		justfiles = append(justfiles, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	
-	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+
+	return justfiles, nil
+}
+
+// walkJustfiles calls fn for every justfile under repoRoot in lexical walk order.
+// Paths that can't be read are passed to onErr (if non-nil) and skipped.
+// The walk stops early when ctx is cancelled or fn returns an error.
+func walkJustfiles(ctx context.Context, repoRoot string, onErr func(path string, err error), fn func(path string) error) error {
+	// Config problems are reported by `j doctor`; fall back to the defaults here
+	ignored := make(map[string]bool)
+	if cfg, err := config.Load(repoRoot); err == nil {
+		for _, name := range cfg.Ignore {
+			ignored[name] = true
+		}
+	}
+
+	return filepath.WalkDir(repoRoot, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
+			if onErr != nil {
+				onErr(path, err)
+			}
 			return nil // Continue walking even if there are errors
 		}
-		
-		// Skip common ignored directories
-		if info.IsDir() && skipDirs[info.Name()] {
-			return filepath.SkipDir
-		}
-		
-		// Skip hidden directories (except .git which we already handle above)
-		if info.IsDir() && len(info.Name()) > 0 && info.Name()[0] == '.' && info.Name() != ".git" {
-			return filepath.SkipDir
+
+		if d.IsDir() {
+			// Never skip the root we were asked to walk
+			if path == repoRoot {
+				return nil
+			}
+			// Skip common ignored directories, configured ones and hidden directories
+			if skipDirs[d.Name()] || ignored[d.Name()] || d.Name()[0] == '.' {
+				return filepath.SkipDir
+			}
+			return nil
 		}
-		
-		if info.Name() == "justfile" && !info.IsDir() {
-			justfiles = append(justfiles, path)
+
+		if d.Name() == "justfile" {
+			return fn(path)
 		}
-		
+
 		return nil
 	})
-	
-	if err != nil {
-		return nil, err
-	}
-	
-	return justfiles, nil
-}
\ No newline at end of file
+}
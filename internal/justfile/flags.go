@@ -0,0 +1,62 @@
+package justfile
+
+import "strings"
+
+// GlobalFlag is one of just's own command-line flags. just only accepts them
+// before the recipe name, so j pulls them out of the command line and places
+// them there itself.
+type GlobalFlag struct {
+	Long  string
+	Short string
+	// Values is how many arguments follow the flag, e.g. 2 for --set NAME VALUE
+	Values int
+}
+
+// GlobalFlags lists the just flags j passes through when running a recipe.
+// Flags j defines itself (--quiet, --verbose, --directory, --list) and ones
+// that change which justfile is used (--justfile, --working-directory) are
+// deliberately left out.
+var GlobalFlags = []GlobalFlag{
+	{Long: "--set", Values: 2},
+	{Long: "--dotenv-path", Short: "-E", Values: 1},
+	{Long: "--dotenv-filename", Values: 1},
+	{Long: "--shell", Values: 1},
+	{Long: "--shell-arg", Values: 1},
+	{Long: "--color", Values: 1},
+	{Long: "--command-color", Values: 1},
+	{Long: "--timestamp-format", Values: 1},
+	{Long: "--tempdir", Values: 1},
+	{Long: "--ceiling", Values: 1},
+	{Long: "--dry-run", Short: "-n"},
+	{Long: "--yes"},
+	{Long: "--no-deps"},
+	{Long: "--no-dotenv"},
+	{Long: "--highlight"},
+	{Long: "--no-highlight"},
+	{Long: "--clear-shell-args"},
+	{Long: "--shell-command"},
+	{Long: "--explain"},
+	{Long: "--timestamps"},
+	{Long: "--unstable"},
+}
+
+// LookupGlobalFlag recognizes arg as a just flag, returning how many of the
+// following arguments are its values. A value given inline as --flag=value
+// counts toward the total.
+func LookupGlobalFlag(arg string) (values int, ok bool) {
+	name, _, inline := strings.Cut(arg, "=")
+	for _, flag := range GlobalFlags {
+		if name != flag.Long && (flag.Short == "" || name != flag.Short) {
+			continue
+		}
+		if inline && flag.Values > 0 {
+			return flag.Values - 1, true
+		}
+		if inline {
+			// Boolean flags don't take --flag=value
+			return 0, false
+		}
+		return flag.Values, true
+	}
+	return 0, false
+}
@@ -2,8 +2,14 @@ package justfile
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
 )
 
 // Target represents a justfile target
@@ -11,6 +17,43 @@ type Target struct {
 	Name         string
 	Description  string
 	JustfilePath string
+	Line         int
+	Signature    string
+	Params       []Param
+	// StartLine and EndLine span the whole recipe: attributes and doc
+	// comment, the header on Line, and the last line of the body
+	StartLine int
+	EndLine   int
+	// Annotations from `# j:` comments directly above the recipe
+	Annotations map[string]string
+}
+
+// Diagnostic describes a problem found while reading or parsing a justfile
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error formats the diagnostic as file:line: message
+func (d Diagnostic) Error() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.File, d.Message)
+}
+
+// Justfile is the parsed form of a single justfile
+type Justfile struct {
+	Path        string
+	Targets     []Target
+	Diagnostics []Diagnostic
+	// Annotations from `# j:` comments at the top of the file, before any
+	// recipe, setting or assignment
+	Annotations map[string]string
+	// Description is the first line of the comment block at the top of the
+	// file, when a blank line separates it from the first recipe
+	Description string
 }
 
 // GetTargets extracts targets from a justfile using `just --list`
@@ -21,72 +64,384 @@ func GetTargets(justfilePath string) ([]Target, error) {
 
 // GetTargetsFromFile parses a justfile directly (fallback method)
 func GetTargetsFromFile(justfilePath string) ([]Target, error) {
+	jf, err := Parse(justfilePath)
+	if err != nil {
+		return nil, err
+	}
+	return jf.Targets, nil
+}
+
+// Parse reads a justfile and extracts its public targets. Problems with the
+// justfile's contents are collected as diagnostics rather than returned, so a
+// single bad line doesn't hide every other target; the error is only for I/O.
+func Parse(justfilePath string) (*Justfile, error) {
 	file, err := os.Open(justfilePath)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	var targets []Target
+	jf := &Justfile{Path: justfilePath, Annotations: make(map[string]string)}
+	report := func(line int, format string, args ...any) {
+		jf.Diagnostics = append(jf.Diagnostics, Diagnostic{
+			File:    justfilePath,
+			Line:    line,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	var (
+		lineNum    int
+		inRecipe   bool
+		expr       exprState
+		comment    string
+		attributes []string
+		// First line of the comment/attribute block above the next recipe
+		blockStart int
+		// Annotations in that block, attached to the recipe if one follows
+		pending map[string]string
+		// Index in jf.Targets of the recipe whose body we're in, or -1
+		current = -1
+		// Every recipe definition, including private ones, for duplicate checks
+		definedAt       = make(map[string]int)
+		duplicates      []Diagnostic
+		allowDuplicates bool
+		// Comment lines at the top of the file, until anything else is seen
+		header    []string
+		seenOther bool
+		// Set until the first line that isn't a comment or blank
+		inHeader = true
+	)
+
+	// flush keeps annotations that turned out not to precede a recipe if
+	// they're at the top of the file, and drops them otherwise
+	flush := func() {
+		if inHeader {
+			for key, value := range pending {
+				jf.Annotations[key] = value
+			}
+		}
+		pending = nil
+	}
+
 	scanner := bufio.NewScanner(file)
-	
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		// Continuation of a multi-line assignment or setting
+		if expr.open() {
+			expr.feed(line)
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			if !seenOther && len(header) > 0 && jf.Description == "" {
+				jf.Description = header[0]
+			}
+			seenOther = seenOther || len(header) > 0
+			comment = ""
+			blockStart = 0
+			flush()
 			continue
 		}
-		
-		// Look for target definitions (lines ending with :)
-		if strings.HasSuffix(line, ":") && !strings.Contains(line, "=") {
-			// Handle targets with parameters like "target param1 param2:"
-			targetLine := strings.TrimSuffix(line, ":")
-			targetLine = strings.TrimSpace(targetLine)
-			
-			// Extract just the target name (first word)
-			parts := strings.Fields(targetLine)
-			if len(parts) > 0 {
-				targetName := parts[0]
-				
-				// Skip internal/private targets that start with _
-				if strings.HasPrefix(targetName, "_") {
-					continue
+
+		if isIndented(line) {
+			if inRecipe {
+				// Recipe body
+				if current >= 0 {
+					jf.Targets[current].EndLine = lineNum
 				}
-				
-				targets = append(targets, Target{
-					Name:         targetName,
+				continue
+			}
+			if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				report(lineNum, "unexpected indented line outside of a recipe")
+			}
+			continue
+		}
+
+		// Any top-level line ends the current recipe body
+		inRecipe = false
+		current = -1
+		if !strings.HasPrefix(line, "#") {
+			seenOther = true
+			if inHeader {
+				// Annotations directly above the first recipe are the
+				// file's too
+				for key, value := range pending {
+					jf.Annotations[key] = value
+				}
+				inHeader = false
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			if annotations, ok := ParseAnnotation(line); ok {
+				if pending == nil {
+					pending = make(map[string]string)
+				}
+				for key, value := range annotations {
+					pending[key] = value
+				}
+			} else {
+				comment = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+				if !seenOther && !strings.HasPrefix(line, "#!") && comment != "" {
+					header = append(header, comment)
+				}
+			}
+			if blockStart == 0 {
+				blockStart = lineNum
+			}
+			continue
+		case strings.HasPrefix(line, "["):
+			attributes = append(attributes, parseAttributes(line)...)
+			if blockStart == 0 {
+				blockStart = lineNum
+			}
+			continue
+		case isStatement(line):
+			if fields := strings.Fields(line); fields[0] == "set" && fields[1] == "allow-duplicate-recipes" {
+				allowDuplicates = !strings.Contains(line, "false")
+			}
+			expr.feed(line)
+		case isAssignment(line):
+			expr.feed(line)
+		default:
+			header, ok := parseHeader(line)
+			if !ok {
+				report(lineNum, "expected recipe, assignment, or setting, found %q", truncate(line, 40))
+				break
+			}
+
+			inRecipe = true
+			if first, ok := definedAt[header.Name]; ok {
+				duplicates = append(duplicates, Diagnostic{
+					File:    justfilePath,
+					Line:    lineNum,
+					Message: fmt.Sprintf("recipe '%s' is already defined on line %d", header.Name, first),
+				})
+			} else {
+				definedAt[header.Name] = lineNum
+			}
+
+			description, private := comment, strings.HasPrefix(header.Name, "_")
+			for _, attr := range attributes {
+				switch attributeName(attr) {
+				case "private":
+					private = true
+				case "doc":
+					description = attributeArgument(attr)
+				}
+			}
+
+			startLine := blockStart
+			if startLine == 0 {
+				startLine = lineNum
+			}
+
+			// Skip internal/private targets that start with _ or are marked [private]
+			if !private {
+				current = len(jf.Targets)
+				jf.Targets = append(jf.Targets, Target{
+					Name:         header.Name,
+					Description:  description,
 					JustfilePath: justfilePath,
+					Line:         lineNum,
+					Signature:    strings.TrimSpace(header.Name + " " + header.Params),
+					Params:       parseParams(header.Params),
+					StartLine:    startLine,
+					EndLine:      lineNum,
+					Annotations:  pending,
 				})
+				pending = nil
+			}
+			// Private recipes aren't listed, so annotations above them are
+			// dropped by the flush below
+		}
+		flush()
+
+		comment = ""
+		attributes = nil
+		blockStart = 0
+	}
+
+	if err := scanner.Err(); err != nil {
+		report(lineNum+1, "%v", err)
+	}
+	flush()
+	if !allowDuplicates {
+		jf.Diagnostics = append(jf.Diagnostics, duplicates...)
+	}
+
+	return jf, nil
+}
+
+// Source returns the lines of the justfile that define target, from its
+// attributes and doc comment through the last line of its body
+func Source(target Target) ([]string, error) {
+	file, err := os.Open(target.JustfilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	lineNum := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNum++
+		if lineNum < target.StartLine {
+			continue
+		}
+		if lineNum > target.EndLine {
+			break
+		}
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// truncate shortens s to at most n runes for use in messages
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
+
+// FileTargets holds the result of parsing a single justfile
+type FileTargets struct {
+	Path        string
+	Targets     []Target
+	Diagnostics []Diagnostic
+	// Annotations from `# j:` comments at the top of the file
+	Annotations map[string]string
+	// Description of the justfile from its header comment
+	Description string
+}
+
+// ParseAll discovers every justfile under repoRoot and parses them concurrently.
+// The walker feeds a bounded pool of parsers; results are returned in walk order
+// no matter which parser finishes first. Cancelling ctx stops both the walk and
+// any parsing that has not started yet.
+func ParseAll(ctx context.Context, repoRoot string) ([]FileTargets, error) {
+	type job struct {
+		index int
+		path  string
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		results []FileTargets
+		wg      sync.WaitGroup
+	)
+
+	jobs := make(chan job)
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if ctx.Err() != nil {
+					continue // Drain remaining jobs without parsing
+				}
+				jf, err := Parse(j.path)
+				mu.Lock()
+				if err != nil {
+					results[j.index].Diagnostics = []Diagnostic{ioDiagnostic(j.path, err)}
+				} else {
+					results[j.index].Targets = jf.Targets
+					results[j.index].Diagnostics = jf.Diagnostics
+					results[j.index].Annotations = jf.Annotations
+					results[j.index].Description = jf.Description
+				}
+				mu.Unlock()
 			}
+		}()
+	}
+
+	// Unreadable directories are reported as diagnostics against the directory
+	// since any justfiles inside them are silently missing otherwise
+	onWalkErr := func(path string, err error) {
+		mu.Lock()
+		results = append(results, FileTargets{Path: path, Diagnostics: []Diagnostic{ioDiagnostic(path, err)}})
+		mu.Unlock()
+	}
+
+	walkErr := walkJustfiles(ctx, repoRoot, onWalkErr, func(path string) error {
+		// Reserve the slot before handing the file to a parser so output order
+		// matches walk order
+		mu.Lock()
+		index := len(results)
+		results = append(results, FileTargets{Path: path})
+		mu.Unlock()
+
+		select {
+		case jobs <- job{index: index, path: path}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
+	})
+	close(jobs)
+	wg.Wait()
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ioDiagnostic converts a filesystem error for path into a diagnostic
+func ioDiagnostic(path string, err error) Diagnostic {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
 	}
-	
-	return targets, scanner.Err()
+	return Diagnostic{File: path, Message: err.Error()}
 }
 
 // GetTargetsFromAllJustfiles gets targets from all justfiles in the repository
 func GetTargetsFromAllJustfiles(repoRoot string) ([]Target, error) {
-	justfiles, err := FindAllJustfiles(repoRoot)
+	return GetTargetsFromAllJustfilesContext(context.Background(), repoRoot)
+}
+
+// GetTargetsFromAllJustfilesContext is GetTargetsFromAllJustfiles with cancellation
+func GetTargetsFromAllJustfilesContext(ctx context.Context, repoRoot string) ([]Target, error) {
+	targets, _, err := GetTargetsAndDiagnostics(ctx, repoRoot)
+	return targets, err
+}
+
+// GetTargetsAndDiagnostics gets targets from all justfiles in the repository
+// along with every problem found while discovering and parsing them
+func GetTargetsAndDiagnostics(ctx context.Context, repoRoot string) ([]Target, []Diagnostic, error) {
+	files, err := ParseAll(ctx, repoRoot)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	
+
 	var allTargets []Target
-	
-	for _, justfilePath := range justfiles {
-		targets, err := GetTargets(justfilePath)
-		if err != nil {
-			// Fallback to file parsing
-			targets, err = GetTargetsFromFile(justfilePath)
-			if err != nil {
-				continue // Skip this justfile if we can't parse it
-			}
-		}
-		
+	var diagnostics []Diagnostic
+
+	for _, file := range files {
+		diagnostics = append(diagnostics, file.Diagnostics...)
+
 		// Add all targets without deduplication - for autocomplete we want all instances
-		allTargets = append(allTargets, targets...)
+		allTargets = append(allTargets, file.Targets...)
 	}
-	
-	return allTargets, nil
-}
\ No newline at end of file
+
+	return allTargets, diagnostics, nil
+}
@@ -0,0 +1,317 @@
+package justfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile creates path under dir with content, making its directories
+func writeFile(t *testing.T, dir, path, content string) string {
+	t.Helper()
+	path = filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseAllOrder(t *testing.T) {
+	t.Setenv("J_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	root := t.TempDir()
+	writeFile(t, root, "justfile", "root:\n  echo root\n")
+	for i := 0; i < 60; i++ {
+		// Vary the nesting so walk order differs from creation order
+		path := filepath.Join(fmt.Sprintf("d%d", i%7), fmt.Sprintf("e%02d", 59-i), "justfile")
+		writeFile(t, root, path, fmt.Sprintf("r%d:\n  echo %d\n", i, i))
+	}
+	writeFile(t, root, "node_modules/x/justfile", "skipped:\n  echo\n")
+
+	want, err := FindAllJustfiles(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := ParseAll(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(want) || len(want) != 61 {
+		t.Fatalf("ParseAll found %d justfiles, FindAllJustfiles %d, want 61", len(files), len(want))
+	}
+	for i, file := range files {
+		if file.Path != want[i] {
+			t.Errorf("ParseAll()[%d] = %s, want %s", i, file.Path, want[i])
+		}
+		if len(file.Targets) != 1 || file.Targets[0].JustfilePath != file.Path {
+			t.Errorf("ParseAll()[%d] has targets %+v from another file", i, file.Targets)
+		}
+	}
+}
+
+func TestParseAllCancelled(t *testing.T) {
+	t.Setenv("J_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	root := t.TempDir()
+	writeFile(t, root, "justfile", "build:\n  echo\n")
+	writeFile(t, root, "a/justfile", "build:\n  echo\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	files, err := ParseAll(ctx, root)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseAll with a cancelled context = %v, want %v", err, context.Canceled)
+	}
+	if files != nil {
+		t.Errorf("ParseAll with a cancelled context returned %d files", len(files))
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	type diagnostic struct {
+		line    int
+		message string
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    []diagnostic
+	}{
+		{
+			name:    "clean",
+			content: "set shell := [\"bash\", \"-c\"]\nversion := \"1\"\n\nbuild:\n  echo\n",
+		},
+		{
+			name:    "stray line",
+			content: "build:\n  echo\nfoo bar baz\n",
+			want:    []diagnostic{{3, `expected recipe, assignment, or setting, found "foo bar baz"`}},
+		},
+		{
+			name:    "indented line outside a recipe",
+			content: "x := \"1\"\n  echo\n",
+			want:    []diagnostic{{2, "unexpected indented line outside of a recipe"}},
+		},
+		{
+			name:    "duplicate recipe",
+			content: "build:\n  echo\n\nbuild:\n  echo\n",
+			want:    []diagnostic{{4, "recipe 'build' is already defined on line 1"}},
+		},
+		{
+			name:    "duplicate of a private recipe",
+			content: "[private]\nbuild:\n  echo\nbuild:\n  echo\n",
+			want:    []diagnostic{{4, "recipe 'build' is already defined on line 2"}},
+		},
+		{
+			name:    "duplicates allowed",
+			content: "set allow-duplicate-recipes\nbuild:\n  echo\nbuild:\n  echo\n",
+		},
+		{
+			name:    "multi-line expressions",
+			content: "x := '''\nfoo bar baz\n'''\ny := (\n  \"a\" +\n  \"b\")\nz := \"a\" + \\\n  \"b\"\nbuild:\n  echo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "justfile", tt.content)
+			jf, err := Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []diagnostic
+			for _, d := range jf.Diagnostics {
+				if d.File != path {
+					t.Errorf("diagnostic %v is for %s, want %s", d, d.File, path)
+				}
+				got = append(got, diagnostic{d.Line, d.Message})
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Parse() diagnostics = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	content := `# Tools for the project
+
+version := "1"
+
+# Build it
+[no-cd]
+build:
+  echo build
+
+  echo done
+test: build
+  echo test
+
+[private]
+hidden:
+  echo
+_internal:
+  echo
+
+[doc('Deploy somewhere')]
+deploy env:
+  echo {{env}}
+`
+	path := writeFile(t, t.TempDir(), "justfile", content)
+	jf, err := Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jf.Description != "Tools for the project" {
+		t.Errorf("Description = %q, want %q", jf.Description, "Tools for the project")
+	}
+
+	type span struct {
+		name        string
+		description string
+		line        int
+		start, end  int
+	}
+	want := []span{
+		{"build", "Build it", 7, 5, 10},
+		{"test", "", 11, 11, 12},
+		{"deploy", "Deploy somewhere", 21, 20, 22},
+	}
+	var got []span
+	for _, target := range jf.Targets {
+		got = append(got, span{target.Name, target.Description, target.Line, target.StartLine, target.EndLine})
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Parse() targets = %v, want %v", got, want)
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		params string
+		want   []Param
+		usage  string
+	}{
+		{params: "", usage: ""},
+		{
+			params: "env",
+			want:   []Param{{Name: "env"}},
+			usage:  "<env>",
+		},
+		{
+			params: `env region="us-east-1" $token`,
+			want: []Param{
+				{Name: "env"},
+				{Name: "region", Default: "us-east-1", HasDefault: true},
+				{Name: "token", Export: true},
+			},
+			usage: "<env> [region=us-east-1] <token>",
+		},
+		{
+			params: "+files",
+			want:   []Param{{Name: "files", Variadic: "+"}},
+			usage:  "<files...>",
+		},
+		{
+			params: "*flags",
+			want:   []Param{{Name: "flags", Variadic: "*"}},
+			usage:  "[flags...]",
+		},
+		{
+			params: "+flags=''",
+			want:   []Param{{Name: "flags", HasDefault: true, Variadic: "+"}},
+			usage:  "[flags...='']",
+		},
+		{
+			params: `msg="hello world" count=(1 + 2) $+rest`,
+			want: []Param{
+				{Name: "msg", Default: "hello world", HasDefault: true},
+				{Name: "count", Default: "(1 + 2)", HasDefault: true},
+				{Name: "rest", Variadic: "+", Export: true},
+			},
+			usage: "[msg='hello world'] [count=(1 + 2)] <rest...>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.params, func(t *testing.T) {
+			got := parseParams(tt.params)
+			if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", tt.want) {
+				t.Errorf("parseParams(%q) = %+v, want %+v", tt.params, got, tt.want)
+			}
+			var usage []string
+			for _, param := range got {
+				usage = append(usage, param.String())
+			}
+			if joined := strings.Join(usage, " "); joined != tt.usage {
+				t.Errorf("usage of %q = %q, want %q", tt.params, joined, tt.usage)
+			}
+		})
+	}
+}
+
+func TestParseAnnotations(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		file    map[string]string
+		recipe  map[string]string
+	}{
+		{
+			name:    "header",
+			content: "# j: name=api\n\nbuild:\n  echo\n",
+			file:    map[string]string{"name": "api"},
+		},
+		{
+			name:    "after the description",
+			content: "# Tools\n\n# j: name=api root\n\nbuild:\n  echo\n",
+			file:    map[string]string{"name": "api", "root": ""},
+		},
+		{
+			name:    "directly above the first recipe",
+			content: "# j: name=api\nbuild:\n  echo\n",
+			file:    map[string]string{"name": "api"},
+			recipe:  map[string]string{"name": "api"},
+		},
+		{
+			name:    "between recipes",
+			content: "build:\n  echo\n\n# j: name=mid\n\ntest:\n  echo\n",
+			file:    map[string]string{},
+		},
+		{
+			name:    "above a later recipe",
+			content: "build:\n  echo\n\n# j: path=file\ntest:\n  echo\n",
+			file:    map[string]string{},
+			recipe:  map[string]string{"path": "file"},
+		},
+		{
+			name:    "above a private recipe",
+			content: "version := \"1\"\n\n# j: name=x\n[private]\nhelper:\n  echo\n",
+			file:    map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "justfile", tt.content)
+			jf, err := Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(jf.Annotations) != fmt.Sprint(tt.file) {
+				t.Errorf("Parse() file annotations = %v, want %v", jf.Annotations, tt.file)
+			}
+			var recipe map[string]string
+			if n := len(jf.Targets); n > 0 {
+				recipe = jf.Targets[n-1].Annotations
+			}
+			if fmt.Sprint(recipe) != fmt.Sprint(tt.recipe) {
+				t.Errorf("Parse() annotations of the last recipe = %v, want %v", recipe, tt.recipe)
+			}
+		})
+	}
+}
@@ -5,20 +5,47 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
 // RunTarget executes a just target in the specified directory with optional arguments
 func RunTarget(justfilePath, target string, args []string, verbose bool) error {
+	return RunTargetWithFlags(justfilePath, nil, target, args, verbose)
+}
+
+// RunTargetWithFlags is RunTarget with flags for just itself, which are placed
+// before the recipe name
+func RunTargetWithFlags(justfilePath string, flags []string, target string, args []string, verbose bool) error {
+	return RunCalls(justfilePath, flags, []Call{{Target: target, Args: args}}, verbose)
+}
+
+// Call is one recipe to run and its arguments
+type Call struct {
+	Target string   `json:"target"`
+	Args   []string `json:"args,omitempty"`
+}
+
+// RunCalls runs several recipes in sequence with a single just invocation,
+// like `just build test lint`. A "--" goes before the first recipe argument
+// that starts with a dash, so just doesn't take it or any later one as a flag.
+func RunCalls(justfilePath string, flags []string, calls []Call, verbose bool) error {
 	dir := filepath.Dir(justfilePath)
 	
-	cmdArgs := append([]string{target}, args...)
+	cmdArgs := append([]string{}, flags...)
+	separated := false
+	for _, call := range calls {
+		cmdArgs = append(cmdArgs, call.Target)
+		for _, arg := range call.Args {
+			if !separated && strings.HasPrefix(arg, "-") {
+				cmdArgs = append(cmdArgs, "--")
+				separated = true
+			}
+			cmdArgs = append(cmdArgs, arg)
+		}
+	}
 	
 	if verbose {
-		if len(args) > 0 {
-			fmt.Printf("Running: cd %s && just %s %v\n", dir, target, args)
-		} else {
-			fmt.Printf("Running: cd %s && just %s\n", dir, target)
-		}
+		fmt.Printf("Running: cd %s && just %s\n", dir, strings.Join(cmdArgs, " "))
 	}
 	
 	cmd := exec.Command("just", cmdArgs...)
@@ -30,21 +57,92 @@ func RunTarget(justfilePath, target string, args []string, verbose bool) error {
 	return cmd.Run()
 }
 
+// PlanCalls validates every target in calls against the justfile and splits
+// trailing arguments that name other recipes into calls of their own, the
+// way just groups `just build test lint`: a recipe takes as many arguments as
+// it has parameters, and the next argument after that starts a new recipe.
+// Calls given separately must be unambiguous on just's command line, so
+// every call but the last must fill all of its recipe's parameters.
+func PlanCalls(justfilePath string, calls []Call) ([]Call, error) {
+	for _, call := range calls {
+		if err := ValidateTarget(justfilePath, call.Target); err != nil {
+			return nil, err
+		}
+	}
+	
+	targets, err := GetTargets(justfilePath)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Target)
+	for _, t := range targets {
+		byName[t.Name] = t
+	}
+	
+	var planned []Call
+	for i, call := range calls {
+		for {
+			max := MaxArgs(byName[call.Target])
+			if max < 0 || len(call.Args) <= max {
+				break
+			}
+			next, ok := byName[call.Args[max]]
+			if !ok {
+				// Too many arguments; reported when arguments are checked
+				break
+			}
+			planned = append(planned, Call{Target: call.Target, Args: call.Args[:max]})
+			call = Call{Target: next.Name, Args: call.Args[max+1:]}
+		}
+		
+		if i < len(calls)-1 {
+			next := calls[i+1].Target
+			switch max := MaxArgs(byName[call.Target]); {
+			case max < 0:
+				return nil, fmt.Errorf("can't run '%s' before '%s' in one just invocation: '%s' takes any number of arguments, so just would pass '%s' to it (run it last)",
+					call.Target, next, call.Target, next)
+			case len(call.Args) < max:
+				return nil, fmt.Errorf("can't run '%s' before '%s' in one just invocation: just would pass '%s' to '%s' as an argument (give all of its arguments)",
+					call.Target, next, next, call.Target)
+			}
+		}
+		planned = append(planned, call)
+	}
+	
+	return planned, nil
+}
+
+// MaxArgs returns how many arguments target accepts, or -1 if it's variadic
+func MaxArgs(target Target) int {
+	for _, param := range target.Params {
+		if param.Variadic != "" {
+			return -1
+		}
+	}
+	return len(target.Params)
+}
+
 
 // ValidateTarget checks if a target exists in the justfile
 func ValidateTarget(justfilePath, target string) error {
+	_, err := LookupTarget(justfilePath, target)
+	return err
+}
+
+// LookupTarget finds a target by name in the justfile
+func LookupTarget(justfilePath, target string) (Target, error) {
 	targets, err := GetTargets(justfilePath)
 	if err != nil {
 		// Fallback to file parsing if just --list fails
 		targets, err = GetTargetsFromFile(justfilePath)
 		if err != nil {
-			return fmt.Errorf("failed to parse justfile: %w", err)
+			return Target{}, fmt.Errorf("failed to parse justfile: %w", err)
 		}
 	}
 	
 	for _, t := range targets {
 		if t.Name == target {
-			return nil
+			return t, nil
 		}
 	}
 	
@@ -53,5 +151,5 @@ func ValidateTarget(justfilePath, target string) error {
 		targetNames = append(targetNames, t.Name)
 	}
 	
-	return fmt.Errorf("target '%s' not found. Available targets: %v", target, targetNames)
+	return Target{}, fmt.Errorf("target '%s' not found. Available targets: %v", target, targetNames)
 }
\ No newline at end of file
@@ -0,0 +1,382 @@
+package justfile
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*\s*:=`)
+)
+
+// statementKeywords start top-level statements that are not recipes
+var statementKeywords = map[string]bool{
+	"set":      true,
+	"alias":    true,
+	"export":   true,
+	"unexport": true,
+	"import":   true,
+	"import?":  true,
+	"mod":      true,
+	"mod?":     true,
+}
+
+// isIndented reports whether a raw justfile line starts with whitespace
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// isStatement reports whether line is a setting, alias, export, import or module
+func isStatement(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 1 && statementKeywords[fields[0]]
+}
+
+// isAssignment reports whether line is a variable assignment
+func isAssignment(line string) bool {
+	return assignmentPattern.MatchString(line)
+}
+
+// recipeHeader is a recipe definition line split into its parts
+type recipeHeader struct {
+	Name   string
+	Params string
+	Deps   string
+	Quiet  bool
+}
+
+// parseHeader splits a recipe header like `@deploy env="dev": build` into its
+// name, parameters and dependencies. It returns false if line is not a header.
+func parseHeader(line string) (recipeHeader, bool) {
+	colon := headerColon(line)
+	if colon < 0 {
+		return recipeHeader{}, false
+	}
+
+	signature := strings.TrimSpace(line[:colon])
+	deps := line[colon+1:]
+	if i := strings.Index(deps, "#"); i >= 0 {
+		deps = deps[:i]
+	}
+
+	var header recipeHeader
+	if strings.HasPrefix(signature, "@") {
+		header.Quiet = true
+		signature = signature[1:]
+	}
+
+	name, params, _ := strings.Cut(signature, " ")
+	if !identifierPattern.MatchString(name) {
+		return recipeHeader{}, false
+	}
+
+	header.Name = name
+	header.Params = strings.TrimSpace(params)
+	header.Deps = strings.TrimSpace(deps)
+	return header, true
+}
+
+// SplitHeader splits a recipe header line into its signature (name and
+// parameters, including any leading @) and the rest of the line after the
+// colon. ok is false if line is not a recipe header.
+func SplitHeader(line string) (signature, rest string, ok bool) {
+	if _, ok := parseHeader(line); !ok {
+		return "", "", false
+	}
+	colon := headerColon(line)
+	return line[:colon], line[colon+1:], true
+}
+
+// headerColon returns the index of the colon ending a recipe signature,
+// ignoring colons inside quoted default values and `:=` assignments
+func headerColon(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '#':
+			return -1
+		case c == ':':
+			if i+1 < len(line) && line[i+1] == '=' {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// exprState tracks whether a multi-line expression (brackets, triple-quoted
+// strings, backslash continuations) is still open across top-level lines
+type exprState struct {
+	depth     int
+	quote     string
+	backslash bool
+}
+
+// feed advances the state past one line of an expression
+func (s *exprState) feed(line string) {
+	for i := 0; i < len(line); i++ {
+		if s.quote != "" {
+			if strings.HasPrefix(line[i:], s.quote) {
+				i += len(s.quote) - 1
+				s.quote = ""
+			}
+			continue
+		}
+
+		rest := line[i:]
+		switch c := line[i]; {
+		case strings.HasPrefix(rest, "'''") || strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, "```"):
+			s.quote = rest[:3]
+			i += 2
+		case c == '\'' || c == '"' || c == '`':
+			for i++; i < len(line) && line[i] != c; i++ {
+				if c == '"' && line[i] == '\\' {
+					i++
+				}
+			}
+		case c == '#':
+			i = len(line)
+		case c == '(' || c == '[' || c == '{':
+			s.depth++
+		case c == ')' || c == ']' || c == '}':
+			s.depth--
+		}
+	}
+
+	s.backslash = s.quote == "" && strings.HasSuffix(strings.TrimRight(line, " \t"), "\\")
+}
+
+// open reports whether the expression continues on the next line
+func (s *exprState) open() bool {
+	return s.quote != "" || s.depth > 0 || s.backslash
+}
+
+// parseAttributes splits an attribute line like `[private, group('ci')]` into
+// individual attributes
+func parseAttributes(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "[")
+	line = strings.TrimSuffix(line, "]")
+
+	var attrs []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			attrs = append(attrs, strings.TrimSpace(line[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(line[start:]); last != "" {
+		attrs = append(attrs, last)
+	}
+
+	return attrs
+}
+
+// attributeArgument returns the first quoted argument of an attribute like
+// `doc("Build it")` or `group: 'ci'`, or "" if there is none
+func attributeArgument(attr string) string {
+	i := strings.IndexAny(attr, "(:")
+	if i < 0 {
+		return ""
+	}
+
+	arg := strings.TrimSpace(attr[i+1:])
+	if arg == "" || (arg[0] != '\'' && arg[0] != '"') {
+		return ""
+	}
+	end := strings.IndexByte(arg[1:], arg[0])
+	if end < 0 {
+		return ""
+	}
+	return arg[1 : end+1]
+}
+
+// attributeName returns the name of an attribute like `group("ci")`
+func attributeName(attr string) string {
+	name, _, _ := strings.Cut(attr, "(")
+	name, _, _ = strings.Cut(name, ":")
+	return strings.TrimSpace(name)
+}
+
+// ParseAnnotation parses a j annotation comment like `# j: name=api root`
+// into its key/value pairs. Bare keys map to "". ok is false if line is not
+// an annotation.
+func ParseAnnotation(line string) (map[string]string, bool) {
+	text, ok := strings.CutPrefix(strings.TrimSpace(line), "#")
+	if !ok {
+		return nil, false
+	}
+	text, ok = strings.CutPrefix(strings.TrimSpace(text), "j:")
+	if !ok {
+		return nil, false
+	}
+
+	annotations := make(map[string]string)
+	for _, field := range strings.Fields(text) {
+		key, value, _ := strings.Cut(field, "=")
+		annotations[key] = value
+	}
+	return annotations, true
+}
+
+// Param is a recipe parameter, e.g. `env`, `region="us-east-1"` or `+files`
+type Param struct {
+	Name string
+	// Default is the default value with any string quotes removed
+	Default    string
+	HasDefault bool
+	// Variadic is "+" (one or more values) or "*" (zero or more), or ""
+	Variadic string
+	// Export is set for `$name` parameters, exported as environment variables
+	Export bool
+}
+
+// Required reports whether a value must be supplied for the parameter
+func (p Param) Required() bool {
+	return !p.HasDefault && p.Variadic != "*"
+}
+
+// String formats the parameter for usage lines: <env>, [region=us-east-1],
+// <files...> or [flags...]. Empty string defaults and ones with whitespace are
+// quoted, as in [flags...=''].
+func (p Param) String() string {
+	name := p.Name
+	if p.Variadic != "" {
+		name += "..."
+	}
+	switch {
+	case p.HasDefault:
+		value := p.Default
+		if value == "" || (strings.ContainsAny(value, " \t") && !strings.HasPrefix(value, "(")) {
+			value = "'" + value + "'"
+		}
+		return "[" + name + "=" + value + "]"
+	case p.Required():
+		return "<" + name + ">"
+	default:
+		return "[" + name + "]"
+	}
+}
+
+// parseParams parses the parameter list of a recipe header, like
+// `env region="us-east-1" +flags`
+func parseParams(params string) []Param {
+	var result []Param
+	for i := 0; i < len(params); {
+		if params[i] == ' ' || params[i] == '\t' {
+			i++
+			continue
+		}
+
+		var param Param
+		for i < len(params) && strings.IndexByte("+*$", params[i]) >= 0 {
+			if params[i] == '$' {
+				param.Export = true
+			} else {
+				param.Variadic = params[i : i+1]
+			}
+			i++
+		}
+
+		start := i
+		for i < len(params) && params[i] != '=' && params[i] != ' ' && params[i] != '\t' {
+			i++
+		}
+		param.Name = params[start:i]
+
+		if i < len(params) && params[i] == '=' {
+			i++
+			end := valueEnd(params, i)
+			param.Default, param.HasDefault = unquote(params[i:end]), true
+			i = end
+		}
+
+		if param.Name != "" {
+			result = append(result, param)
+		}
+	}
+	return result
+}
+
+// valueEnd returns the index just past the default value starting at i: a
+// quoted string, a parenthesized expression or a bare word
+func valueEnd(s string, i int) int {
+	if i >= len(s) {
+		return i
+	}
+
+	switch c := s[i]; c {
+	case '\'', '"', '`':
+		if strings.HasPrefix(s[i:], strings.Repeat(string(c), 3)) {
+			if end := strings.Index(s[i+3:], strings.Repeat(string(c), 3)); end >= 0 {
+				return i + 3 + end + 3
+			}
+			return len(s)
+		}
+		for j := i + 1; j < len(s); j++ {
+			if c == '"' && s[j] == '\\' {
+				j++
+			} else if s[j] == c {
+				return j + 1
+			}
+		}
+		return len(s)
+	case '(':
+		depth := 0
+		for j := i; j < len(s); j++ {
+			switch s[j] {
+			case '\'', '"':
+				if end := strings.IndexByte(s[j+1:], s[j]); end >= 0 {
+					j += end + 1
+				}
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return len(s)
+	default:
+		end := strings.IndexAny(s[i:], " \t")
+		if end < 0 {
+			return len(s)
+		}
+		return i + end
+	}
+}
+
+// unquote strips the quotes from a simple string literal default value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,454 @@
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sleexyz/j/internal/fuzzy"
+	"github.com/sleexyz/j/internal/history"
+	"github.com/sleexyz/j/internal/justfile"
+	"github.com/sleexyz/j/internal/ui"
+)
+
+// ErrCancelled is returned when the picker is closed without choosing
+var ErrCancelled = errors.New("cancelled")
+
+// Item is a recipe offered by the picker
+type Item struct {
+	Target justfile.Target
+	// Dir is the @path of the recipe's directory
+	Dir string
+	// Frecency ranks items that have been run often and recently first
+	Frecency float64
+}
+
+// Options configure the picker
+type Options struct {
+	// In is the terminal keys are read from; Out is where the picker is drawn
+	In  *os.File
+	Out *os.File
+	// Color enables colored output
+	Color bool
+	// Preview returns the lines shown below the list for the selected item,
+	// which may contain ANSI colors. There's no preview pane if it's nil.
+	Preview func(item Item) []string
+}
+
+// Pick shows items full screen on the terminal, filtered by what the user
+// types, and returns the one they choose with Enter
+func Pick(items []Item, opts Options) (Item, error) {
+	restore, err := makeRaw(int(opts.In.Fd()))
+	if err != nil {
+		return Item{}, err
+	}
+	defer restore()
+
+	out := bufio.NewWriter(opts.Out)
+	// Draw on the alternate screen, leaving the shell's scrollback alone
+	out.WriteString("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		out.WriteString("\x1b[?25h\x1b[?1049l")
+		out.Flush()
+	}()
+
+	m := newModel(items, opts)
+	buf := make([]byte, 256)
+	for {
+		width, height, err := windowSize(int(opts.Out.Fd()))
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		out.WriteString(m.render(width, height))
+		if err := out.Flush(); err != nil {
+			return Item{}, err
+		}
+
+		n, err := opts.In.Read(buf)
+		if err != nil {
+			return Item{}, err
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			switch m.handle(k) {
+			case actionChoose:
+				if item, ok := m.current(); ok {
+					return item, nil
+				}
+			case actionCancel:
+				return Item{}, ErrCancelled
+			}
+		}
+	}
+}
+
+// Special keys; printable keys are runes
+const (
+	keyRune = iota
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyEnter
+	keyBackspace
+	keyDeleteWord
+	keyClear
+	keyCancel
+	keyIgnore
+)
+
+// key is one key press
+type key struct {
+	kind int
+	r    rune
+}
+
+// parseKeys decodes the bytes of one read from a raw terminal into keys
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		c := b[0]
+		switch {
+		case c == 27 && len(b) == 1:
+			// A lone Escape, not the start of a sequence
+			keys = append(keys, key{kind: keyCancel})
+			b = b[1:]
+		case c == 27:
+			k, n := parseEscape(b)
+			keys = append(keys, k)
+			b = b[n:]
+		default:
+			k, n := parseByte(b)
+			keys = append(keys, k)
+			b = b[n:]
+		}
+	}
+	return keys
+}
+
+// parseEscape decodes an escape sequence like ESC [ A, returning the key
+// and the sequence's length
+func parseEscape(b []byte) (key, int) {
+	if b[1] != '[' && b[1] != 'O' {
+		// Alt+key
+		return key{kind: keyIgnore}, 2
+	}
+
+	// Parameters, then a final byte in @..~
+	end := 2
+	for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+		end++
+	}
+	if end == len(b) {
+		return key{kind: keyIgnore}, len(b)
+	}
+
+	switch string(b[2 : end+1]) {
+	case "A":
+		return key{kind: keyUp}, end + 1
+	case "B":
+		return key{kind: keyDown}, end + 1
+	case "5~":
+		return key{kind: keyPageUp}, end + 1
+	case "6~":
+		return key{kind: keyPageDown}, end + 1
+	default:
+		return key{kind: keyIgnore}, end + 1
+	}
+}
+
+// parseByte decodes a control character or UTF-8 encoded rune at the start
+// of b, returning the key and how many bytes it used
+func parseByte(b []byte) (key, int) {
+	switch b[0] {
+	case '\r', '\n':
+		return key{kind: keyEnter}, 1
+	case 127, 8:
+		return key{kind: keyBackspace}, 1
+	case 3, 4, 7: // Ctrl-C, Ctrl-D, Ctrl-G
+		return key{kind: keyCancel}, 1
+	case 16, 11: // Ctrl-P, Ctrl-K
+		return key{kind: keyUp}, 1
+	case 14: // Ctrl-N
+		return key{kind: keyDown}, 1
+	case 21: // Ctrl-U
+		return key{kind: keyClear}, 1
+	case 23: // Ctrl-W
+		return key{kind: keyDeleteWord}, 1
+	}
+	if b[0] < 32 {
+		return key{kind: keyIgnore}, 1
+	}
+
+	r, n := utf8.DecodeRune(b)
+	if r == utf8.RuneError {
+		return key{kind: keyIgnore}, n
+	}
+	return key{kind: keyRune, r: r}, n
+}
+
+// Results of handling a key
+const (
+	actionNone = iota
+	actionChoose
+	actionCancel
+)
+
+// model is the picker's state: the query, the items matching it and the
+// selection
+type model struct {
+	items []Item
+	opts  Options
+	query []rune
+	// matches index items, best match first
+	matches []int
+	// selected indexes matches; offset is the first match shown
+	selected int
+	offset   int
+	previews map[int][]string
+}
+
+func newModel(items []Item, opts Options) *model {
+	m := &model{items: items, opts: opts, previews: make(map[int][]string)}
+	m.filter()
+	return m
+}
+
+// filter matches the items against the query, ranking fuzzy matches on the
+// name and directory above matches on the description, and recipes run
+// often and recently first
+func (m *model) filter() {
+	query := string(m.query)
+
+	type scored struct {
+		index, score int
+	}
+	var results []scored
+	for i, item := range m.items {
+		score := history.Boost(item.Frecency)
+		if query != "" {
+			match := fuzzy.Score(query, item.Target.Name+" "+item.Dir)
+			if match == 0 {
+				match = fuzzy.Score(query, item.Target.Description) / 2
+			}
+			if match == 0 {
+				continue
+			}
+			score += match
+		}
+		results = append(results, scored{i, score})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	m.matches = m.matches[:0]
+	for _, result := range results {
+		m.matches = append(m.matches, result.index)
+	}
+	m.selected, m.offset = 0, 0
+}
+
+// current returns the selected item, if anything matches
+func (m *model) current() (Item, bool) {
+	if len(m.matches) == 0 {
+		return Item{}, false
+	}
+	return m.items[m.matches[m.selected]], true
+}
+
+// handle applies a key press to the model
+func (m *model) handle(k key) int {
+	switch k.kind {
+	case keyRune:
+		m.query = append(m.query, k.r)
+		m.filter()
+	case keyBackspace:
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+			m.filter()
+		}
+	case keyDeleteWord:
+		trimmed := strings.TrimRight(string(m.query), " ")
+		m.query = []rune(trimmed[:strings.LastIndex(trimmed, " ")+1])
+		m.filter()
+	case keyClear:
+		m.query = m.query[:0]
+		m.filter()
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case keyPageUp:
+		m.move(-10)
+	case keyPageDown:
+		m.move(10)
+	case keyEnter:
+		return actionChoose
+	case keyCancel:
+		return actionCancel
+	}
+	return actionNone
+}
+
+// move moves the selection by delta, stopping at either end
+func (m *model) move(delta int) {
+	m.selected = max(0, min(m.selected+delta, len(m.matches)-1))
+}
+
+// render draws the whole screen: the query line, the list and the preview
+func (m *model) render(width, height int) string {
+	listHeight := height - 1
+	showPreview := m.opts.Preview != nil && height >= 12
+	if showPreview {
+		listHeight = (height - 1) / 2
+	}
+
+	// Keep the selection in view
+	if m.selected < m.offset {
+		m.offset = m.selected
+	}
+	if m.selected >= m.offset+listHeight {
+		m.offset = m.selected - listHeight + 1
+	}
+
+	lines := []string{m.promptLine(width)}
+
+	// Align the directory and description columns over what's visible
+	nameWidth, dirWidth := 0, 0
+	for row := 0; row < listHeight && m.offset+row < len(m.matches); row++ {
+		item := m.items[m.matches[m.offset+row]]
+		nameWidth = max(nameWidth, utf8.RuneCountInString(signature(item.Target)))
+		dirWidth = max(dirWidth, utf8.RuneCountInString(item.Dir))
+	}
+	nameWidth, dirWidth = min(nameWidth, 40), min(dirWidth, 30)
+
+	for row := 0; row < listHeight; row++ {
+		index := m.offset + row
+		if index >= len(m.matches) {
+			lines = append(lines, "")
+			continue
+		}
+		item := m.items[m.matches[index]]
+		lines = append(lines, m.itemLine(item, index == m.selected, nameWidth, dirWidth, width))
+	}
+
+	if showPreview {
+		lines = append(lines, m.previewLines(width, height-len(lines))...)
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+	return b.String()
+}
+
+// promptLine shows the query and how many items match it
+func (m *model) promptLine(width int) string {
+	count := fmt.Sprintf("  %d/%d", len(m.matches), len(m.items))
+	return fit(m.paint("> ", ui.Bold, ui.Cyan)+string(m.query)+m.paint("▏", ui.Dim)+m.paint(count, ui.Dim), width)
+}
+
+// itemLine shows one recipe: its name and parameters, directory and
+// description
+func (m *model) itemLine(item Item, selected bool, nameWidth, dirWidth, width int) string {
+	pointer := "  "
+	name := m.paint(pad(signature(item.Target), nameWidth), ui.Bold)
+	if selected {
+		pointer = m.paint("▸ ", ui.Bold, ui.Cyan)
+		name = m.paint(pad(signature(item.Target), nameWidth), ui.Bold, ui.Cyan)
+	}
+	dir := m.paint(pad(item.Dir, dirWidth), ui.Blue)
+	description := m.paint(item.Target.Description, ui.Dim)
+	return fit(pointer+name+"  "+dir+"  "+description, width)
+}
+
+// previewLines shows the selected recipe's source under a divider
+func (m *model) previewLines(width, height int) []string {
+	item, ok := m.current()
+	if !ok || height <= 0 {
+		return nil
+	}
+
+	title := fmt.Sprintf("── %s %s ", item.Dir, item.Target.Name)
+	divider := title + strings.Repeat("─", max(0, width-utf8.RuneCountInString(title)))
+	lines := []string{fit(m.paint(divider, ui.Dim), width)}
+
+	index := m.matches[m.selected]
+	preview, ok := m.previews[index]
+	if !ok {
+		preview = m.opts.Preview(item)
+		m.previews[index] = preview
+	}
+	for _, line := range preview {
+		if len(lines) == height {
+			break
+		}
+		lines = append(lines, fit(strings.ReplaceAll(line, "\t", "    "), width))
+	}
+	return lines
+}
+
+func (m *model) paint(s string, codes ...string) string {
+	if !m.opts.Color {
+		return s
+	}
+	return ui.Paint(s, codes...)
+}
+
+// signature shows a recipe's name and parameters, like `deploy <env>`
+func signature(target justfile.Target) string {
+	return strings.TrimSpace(target.Name + " " + target.Usage())
+}
+
+// pad truncates or pads s with spaces to width runes
+func pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		return string([]rune(s)[:max(0, width-1)]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// fit truncates s to width visible runes, skipping over ANSI escape
+// sequences and resetting colors if it cuts them short
+func fit(s string, width int) string {
+	var b strings.Builder
+	visible := 0
+	colored := false
+	for i := 0; i < len(s); {
+		if s[i] == 27 && i+1 < len(s) && s[i+1] == '[' {
+			end := i + 2
+			for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+				end++
+			}
+			end = min(end+1, len(s))
+			b.WriteString(s[i:end])
+			colored = s[i:end] != "\x1b[0m" && s[i:end] != "\x1b[m"
+			i = end
+			continue
+		}
+
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if visible == width {
+			if colored {
+				b.WriteString("\x1b[0m")
+			}
+			break
+		}
+		b.WriteRune(r)
+		visible++
+		i += n
+	}
+	return b.String()
+}
@@ -0,0 +1,184 @@
+package picker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []key
+	}{
+		{name: "text", input: "aé", want: []key{{keyRune, 'a'}, {keyRune, 'é'}}},
+		{name: "lone escape", input: "\x1b", want: []key{{kind: keyCancel}}},
+		{name: "arrows", input: "\x1b[A\x1b[B\x1bOA", want: []key{{kind: keyUp}, {kind: keyDown}, {kind: keyUp}}},
+		{name: "pages", input: "\x1b[5~\x1b[6~", want: []key{{kind: keyPageUp}, {kind: keyPageDown}}},
+		{name: "alt", input: "\x1bxa", want: []key{{kind: keyIgnore}, {keyRune, 'a'}}},
+		{name: "unknown sequence", input: "\x1b[1;5Cb", want: []key{{kind: keyIgnore}, {keyRune, 'b'}}},
+		{name: "incomplete sequence", input: "\x1b[1", want: []key{{kind: keyIgnore}}},
+		{
+			name:  "control keys",
+			input: "\r\n\x7f\x08\x03\x04\x07\x10\x0b\x0e\x15\x17\x01",
+			want: []key{
+				{kind: keyEnter}, {kind: keyEnter},
+				{kind: keyBackspace}, {kind: keyBackspace},
+				{kind: keyCancel}, {kind: keyCancel}, {kind: keyCancel},
+				{kind: keyUp}, {kind: keyUp}, {kind: keyDown},
+				{kind: keyClear}, {kind: keyDeleteWord}, {kind: keyIgnore},
+			},
+		},
+		{name: "invalid UTF-8", input: "\xffa", want: []key{{kind: keyIgnore}, {keyRune, 'a'}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseKeys([]byte(tt.input)); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("parseKeys(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// testItems are recipes in three directories; test has been run before
+func testItems() []Item {
+	return []Item{
+		{Target: justfile.Target{Name: "build"}, Dir: "@"},
+		{Target: justfile.Target{Name: "deploy", Description: "Ship it"}, Dir: "@services/api"},
+		{Target: justfile.Target{Name: "test"}, Dir: "@web", Frecency: 2},
+	}
+}
+
+// names lists the names of the items matching the model's query, in order
+func names(m *model) []string {
+	var names []string
+	for _, i := range m.matches {
+		names = append(names, m.items[i].Target.Name)
+	}
+	return names
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// History first, then the order given
+		{query: "", want: []string{"test", "build", "deploy"}},
+		{query: "dep", want: []string{"deploy"}},
+		{query: "api", want: []string{"deploy"}},
+		// Descriptions match too
+		{query: "ship", want: []string{"deploy"}},
+		{query: "zzz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			m := newModel(testItems(), Options{})
+			m.query = []rune(tt.query)
+			m.filter()
+			if got := names(m); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("filter(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			if _, ok := m.current(); ok != (len(tt.want) > 0) {
+				t.Errorf("current() after filter(%q) reports %v", tt.query, ok)
+			}
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	m := newModel(testItems(), Options{})
+	press := func(keys ...key) int {
+		t.Helper()
+		action := actionNone
+		for _, k := range keys {
+			action = m.handle(k)
+		}
+		return action
+	}
+	typed := func(s string) []key {
+		var keys []key
+		for _, r := range s {
+			keys = append(keys, key{keyRune, r})
+		}
+		return keys
+	}
+
+	press(key{kind: keyUp})
+	if m.selected != 0 {
+		t.Errorf("moving up from the top selected %d", m.selected)
+	}
+	press(key{kind: keyPageDown})
+	if item, _ := m.current(); item.Target.Name != "deploy" {
+		t.Errorf("page down selected %s, want the last item", item.Target.Name)
+	}
+
+	press(typed("bui x")...)
+	if string(m.query) != "bui x" || len(m.matches) != 0 {
+		t.Errorf("query %q matches %v", string(m.query), names(m))
+	}
+	press(key{kind: keyDeleteWord})
+	if string(m.query) != "bui " {
+		t.Errorf("Ctrl-W left %q, want %q", string(m.query), "bui ")
+	}
+	press(key{kind: keyBackspace}, key{kind: keyBackspace})
+	if got := names(m); string(m.query) != "bu" || fmt.Sprint(got) != "[build]" {
+		t.Errorf("query %q matches %v, want [build]", string(m.query), got)
+	}
+	if action := press(key{kind: keyEnter}); action != actionChoose {
+		t.Errorf("Enter = %d, want actionChoose", action)
+	}
+
+	press(key{kind: keyClear})
+	if len(m.query) != 0 || len(m.matches) != 3 {
+		t.Errorf("Ctrl-U left query %q matching %v", string(m.query), names(m))
+	}
+	if action := press(key{kind: keyCancel}); action != actionCancel {
+		t.Errorf("Escape = %d, want actionCancel", action)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{s: "hello", width: 3, want: "hel"},
+		{s: "hi", width: 5, want: "hi"},
+		{s: "héllo", width: 2, want: "hé"},
+		{s: "hello", width: 0, want: ""},
+		{s: "\x1b[1mhello\x1b[0m", width: 3, want: "\x1b[1mhel\x1b[0m"},
+		{s: "\x1b[1mhi\x1b[0m", width: 2, want: "\x1b[1mhi\x1b[0m"},
+		{s: "a\x1b[34mbc\x1b[0md", width: 3, want: "a\x1b[34mbc\x1b[0m"},
+	}
+
+	for _, tt := range tests {
+		if got := fit(tt.s, tt.width); got != tt.want {
+			t.Errorf("fit(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{s: "ab", width: 4, want: "ab  "},
+		{s: "abcd", width: 4, want: "abcd"},
+		{s: "abcde", width: 4, want: "abc…"},
+		{s: "héllo", width: 3, want: "hé…"},
+	}
+
+	for _, tt := range tests {
+		if got := pad(tt.s, tt.width); got != tt.want {
+			t.Errorf("pad(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package picker
+
+import "errors"
+
+var errUnsupported = errors.New("the picker isn't supported on this platform; use j list")
+
+func makeRaw(fd int) (func() error, error) {
+	return nil, errUnsupported
+}
+
+func windowSize(fd int) (width, height int, err error) {
+	return 0, 0, errUnsupported
+}
@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sleexyz/j/internal/config"
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+// AliasAnnotation is the `# j:` annotation that names a justfile's directory,
+// e.g. `# j: name=api` makes @api resolve to it
+const AliasAnnotation = "name"
+
+// Alias is a short name for a directory in the repo, usable as @name
+type Alias struct {
+	Name string `json:"name"`
+	// Path is the directory relative to the repo root, slash separated
+	Path string `json:"path"`
+	// Source is the config file or justfile that defines the alias
+	Source string `json:"source"`
+}
+
+// aliasing memoizes Aliases for the life of the process, since every @path
+// that isn't a directory is checked against the aliases
+var aliasing struct {
+	sync.Mutex
+	results map[string]aliasesResult
+}
+
+type aliasesResult struct {
+	aliases []Alias
+	err     error
+}
+
+// Aliases returns every alias definition for the repo at repoRoot in
+// precedence order: the repo config, the user config, then justfile
+// annotations in walk order. A name may be defined more than once; the first
+// definition wins. Results are cached per repo root.
+func Aliases(ctx context.Context, repoRoot string) ([]Alias, error) {
+	aliasing.Lock()
+	defer aliasing.Unlock()
+
+	if result, ok := aliasing.results[repoRoot]; ok {
+		return result.aliases, result.err
+	}
+
+	var result aliasesResult
+	files, err := justfile.ParseAll(ctx, repoRoot)
+	if err != nil {
+		// A cancelled walk may succeed next time, so don't remember it
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		result.err = err
+	} else {
+		result.aliases = AliasesFromFiles(repoRoot, files)
+	}
+
+	if aliasing.results == nil {
+		aliasing.results = make(map[string]aliasesResult)
+	}
+	aliasing.results[repoRoot] = result
+	return result.aliases, result.err
+}
+
+// AliasesFromFiles is Aliases for justfiles that have already been parsed
+func AliasesFromFiles(repoRoot string, files []justfile.FileTargets) []Alias {
+	var aliases []Alias
+
+	// Invalid config files are reported by `j doctor`; skip them here
+	paths := config.Paths(repoRoot)
+	slices.Reverse(paths)
+	for _, path := range paths {
+		cfg, err := config.LoadFile(path)
+		if err != nil {
+			continue
+		}
+		names := make([]string, 0, len(cfg.Aliases))
+		for name := range cfg.Aliases {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			aliases = append(aliases, Alias{
+				Name:   name,
+				Path:   filepath.ToSlash(filepath.Clean(cfg.Aliases[name])),
+				Source: path,
+			})
+		}
+	}
+
+	for _, file := range files {
+		name, ok := file.Annotations[AliasAnnotation]
+		if !ok || config.ValidateAlias(name) != nil {
+			continue
+		}
+		rel, err := filepath.Rel(repoRoot, filepath.Dir(file.Path))
+		if err != nil {
+			continue
+		}
+		aliases = append(aliases, Alias{Name: name, Path: filepath.ToSlash(rel), Source: file.Path})
+	}
+
+	return aliases
+}
+
+// AliasNames maps each directory (relative to the repo root, slash separated)
+// to the alias names that win for it
+func AliasNames(aliases []Alias) map[string][]string {
+	names := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, alias := range aliases {
+		if seen[alias.Name] {
+			continue
+		}
+		seen[alias.Name] = true
+		names[alias.Path] = append(names[alias.Path], alias.Name)
+	}
+	return names
+}
+
+// expandAlias rewrites an @path whose first component is an alias, so both
+// @api and @api/sub work. It returns the alias that matched, if any.
+func expandAlias(relPath, repoRoot string) (string, *Alias) {
+	name, rest, _ := strings.Cut(filepath.ToSlash(relPath), "/")
+	if config.ValidateAlias(name) != nil {
+		return relPath, nil
+	}
+
+	aliases, err := Aliases(context.Background(), repoRoot)
+	if err != nil {
+		return relPath, nil
+	}
+	for _, alias := range aliases {
+		if alias.Name == name {
+			return filepath.Join(filepath.FromSlash(alias.Path), rest), &alias
+		}
+	}
+	return relPath, nil
+}
+
+// LookupAlias resolves relPath, an alias optionally followed by a path below
+// it (api or api/sub), against repoRoot. Unlike LookupRepoPath it never
+// tries relPath as a directory first, so a directory named like the alias
+// can't shadow it; qualified targets like api::build resolve this way.
+func LookupAlias(relPath, repoRoot string) (*Resolution, error) {
+	relPath = strings.TrimPrefix(relPath, "@")
+	expanded, alias := expandAlias(relPath, repoRoot)
+	if alias == nil {
+		return nil, &PathError{Path: "@" + relPath, Root: repoRoot, Err: ErrNoAlias}
+	}
+	return lookupExpanded(relPath, expanded, alias, repoRoot)
+}
+
+// lookupExpanded resolves the @path relPath, which expandAlias rewrote to
+// expanded through alias
+func lookupExpanded(relPath, expanded string, alias *Alias, repoRoot string) (*Resolution, error) {
+	resolution, err := lookupRepoPath(expanded, repoRoot, false)
+	var pathErr *PathError
+	if errors.As(err, &pathErr) {
+		err = fmt.Errorf("alias %s (defined in %s) points to %s: %w", alias.Name, alias.Source, pathErr.Path, pathErr.Err)
+	}
+	if err != nil {
+		return nil, &PathError{Path: "@" + relPath, Root: repoRoot, Err: err}
+	}
+	resolution.Alias = alias.Name
+	return resolution, nil
+}
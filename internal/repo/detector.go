@@ -1,48 +1,240 @@
 package repo
 
 import (
+	"errors"
+	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+
+	"github.com/sleexyz/j/internal/config"
 )
 
-// FindRepoRoot finds the root of the git repository
-func FindRepoRoot() (string, error) {
-	// Try git rev-parse first
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
-	if err == nil {
-		return strings.TrimSpace(string(output)), nil
+// Root is a detected repository root and how it was found
+type Root struct {
+	// Path is the root @paths resolve against. Inside a git submodule this is
+	// the outermost superproject unless configured otherwise.
+	Path string `json:"path"`
+	// Inner is the innermost repository containing the current directory,
+	// used by @@path and @/path. It equals Path when repos aren't nested.
+	Inner    string `json:"inner"`
+	Detector string `json:"detector"`
+}
+
+// Resolve resolves an @path against the root. @@path and @/path are relative
+// to the innermost repository; any other @path is relative to Path.
+func (r *Root) Resolve(repoPath string) (string, error) {
+	resolution, err := r.Lookup(repoPath)
+	if err != nil {
+		return "", err
 	}
+	return resolution.Path, nil
+}
+
+// Lookup is Resolve, also reporting the canonical @path and whether it was
+// matched from an abbreviation
+func (r *Root) Lookup(repoPath string) (*Resolution, error) {
+	if IsRelative(repoPath) {
+		return r.lookupRelative(repoPath)
+	}
+
+	base, prefix := r.Path, "@"
+	for _, p := range []string{"@@", "@/"} {
+		if rest, ok := strings.CutPrefix(repoPath, p); ok {
+			base, prefix, repoPath = r.Inner, p, rest
+			break
+		}
+	}
+
+	resolution, err := LookupRepoPath(repoPath, base)
+	if err != nil {
+		return nil, err
+	}
+	resolution.RepoPath = prefix + strings.TrimPrefix(resolution.RepoPath, "@")
+	return resolution, nil
+}
+
+// LookupAlias resolves an alias, optionally followed by a path below it,
+// against the root, never as a directory. See LookupAlias.
+func (r *Root) LookupAlias(relPath string) (*Resolution, error) {
+	return LookupAlias(relPath, r.Path)
+}
+
+// IsRelative reports whether an @path is relative to the current directory:
+// @., @.., @./path or @../path
+func IsRelative(repoPath string) bool {
+	rest := strings.TrimPrefix(repoPath, "@")
+	return rest == "." || rest == ".." || strings.HasPrefix(rest, "./") || strings.HasPrefix(rest, "../")
+}
 
-	// Fallback to current working directory
+// lookupRelative resolves an @path relative to the current directory. It must
+// still stay inside the repo, and is never expanded as an alias or
+// abbreviation.
+func (r *Root) lookupRelative(repoPath string) (*Resolution, error) {
 	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(r.Path, filepath.Join(cwd, strings.TrimPrefix(repoPath, "@")))
+	if err != nil {
+		return nil, err
+	}
+
+	resolution, err := lookupRepoPath(rel, r.Path, false)
+	var pathErr *PathError
+	if errors.As(err, &pathErr) {
+		// Report the path as the user wrote it
+		pathErr.Path = repoPath
+	}
+	return resolution, err
+}
+
+// RootEnv names the environment variable that overrides root detection
+const RootEnv = "J_ROOT"
+
+// ErrNoRepoRoot is returned when no detector recognizes a repository root
+var ErrNoRepoRoot = errors.New("no repository detected")
+
+// FindRepoRoot finds the root of the repository containing the current directory
+func FindRepoRoot() (string, error) {
+	root, err := DetectRepoRoot()
 	if err != nil {
 		return "", err
 	}
-	return cwd, nil
+	return root.Path, nil
 }
 
-// IsGitRepo checks if the current directory is inside a git repository
-func IsGitRepo() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	return cmd.Run() == nil
+// detection memoizes Detect for the life of the process, since completion
+// resolves the root several times per keystroke
+var detection struct {
+	sync.Mutex
+	results map[string]detectResult
+}
+
+type detectResult struct {
+	root *Root
+	err  error
+}
+
+// DetectRepoRoot finds the repository root containing the current directory,
+// recording which detector found it
+func DetectRepoRoot() (*Root, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return Detect(cwd)
 }
 
-// ResolveRepoPath resolves @path/to/dir to actual filesystem path
-func ResolveRepoPath(repoPath, repoRoot string) (string, error) {
-	// Remove @ prefix
-	if strings.HasPrefix(repoPath, "@") {
-		repoPath = strings.TrimPrefix(repoPath, "@")
+// Detect finds the repository root containing dir. J_ROOT always wins; then
+// the nearest explicit marker; then the nearest version control root.
+// Results are cached per directory.
+func Detect(dir string) (*Root, error) {
+	detection.Lock()
+	defer detection.Unlock()
+
+	if result, ok := detection.results[dir]; ok {
+		return result.root, result.err
 	}
 
-	fullPath := filepath.Join(repoRoot, repoPath)
-	
-	// Check if directory exists
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-		return "", err
+	root, err := detect(dir)
+	if detection.results == nil {
+		detection.results = make(map[string]detectResult)
+	}
+	detection.results[dir] = detectResult{root: root, err: err}
+	return root, err
+}
+
+func detect(dir string) (*Root, error) {
+	root, err := detectOuter(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The innermost repository is the nearest version control root inside
+	// the detected root, e.g. one repo in a workspace marked with .j-root
+	root.Inner = root.Path
+	if inner := nearest(dir, VCSDetectors); inner != nil && within(inner.Path, root.Path) {
+		root.Inner = inner.Path
+	}
+
+	// Inside a git submodule, resolve against the superproject by default
+	if root.Detector == "git" {
+		if super, ok := gitSuperproject(root.Path); ok {
+			root.Path = super
+		}
+	}
+	if cfg, err := config.Load(root.Path); err == nil && cfg.NestedRoots == "innermost" {
+		root.Path = root.Inner
+	}
+
+	return root, nil
+}
+
+// within reports whether path is root or inside it
+func within(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// detectOuter applies the detectors in priority order
+func detectOuter(dir string) (*Root, error) {
+	if path := os.Getenv(RootEnv); path != "" {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("%s=%s is not a directory", RootEnv, path)
+		}
+		return &Root{Path: path, Detector: RootEnv}, nil
 	}
 
-	return fullPath, nil
-}
\ No newline at end of file
+	// With GIT_DIR set, only git itself knows where the work tree is
+	if os.Getenv("GIT_DIR") != "" {
+		if path, ok := gitCommandRoot(dir); ok {
+			return &Root{Path: path, Detector: "git"}, nil
+		}
+	}
+
+	for _, detectors := range [][]Detector{MarkerDetectors, VCSDetectors} {
+		if root := nearest(dir, detectors); root != nil {
+			return root, nil
+		}
+	}
+
+	// Last resort for git layouts the native walk doesn't understand
+	if path, ok := gitCommandRoot(dir); ok {
+		return &Root{Path: path, Detector: "git"}, nil
+	}
+
+	return nil, fmt.Errorf("%w from %s (set %s, add a %s file, or run inside a git, jj, hg or sapling repository)",
+		ErrNoRepoRoot, dir, RootEnv, rootMarkers()[0])
+}
+
+// nearest runs every detector and returns the deepest root found. Ties go to
+// the detector listed first.
+func nearest(dir string, detectors []Detector) *Root {
+	var best *Root
+	for _, detector := range detectors {
+		path, ok := detector.Detect(dir)
+		if ok && (best == nil || len(path) > len(best.Path)) {
+			best = &Root{Path: path, Detector: detector.Name}
+		}
+	}
+	return best
+}
+
+// IsGitRepo checks if the current directory is inside a git repository
+func IsGitRepo() bool {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	if _, ok := detectGit(cwd); ok {
+		return true
+	}
+	_, ok := gitCommandRoot(cwd)
+	return ok
+}
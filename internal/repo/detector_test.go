@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sleexyz/j/internal/testrepo"
+)
+
+// isolate keeps the environment and user config out of root detection
+func isolate(t *testing.T) {
+	t.Helper()
+	t.Setenv(RootEnv, "")
+	t.Setenv("GIT_DIR", "")
+	t.Setenv("J_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		dir      string
+		want     string
+		detector string
+	}{
+		{
+			name: "settings don't mark a root",
+			files: map[string]string{
+				".git/":                 "",
+				"justfile":              "set shell := [\"bash\", \"-c\"]\n",
+				"services/api/justfile": "set dotenv-load\n\ndev:\n  echo\n",
+			},
+			dir:      "services/api",
+			want:     ".",
+			detector: "git",
+		},
+		{
+			name: "marked justfile inside the repository",
+			files: map[string]string{
+				".git/":            "",
+				"tools/justfile":   "# Tools\n# j: root\n\nbuild:\n  echo\n",
+				"tools/x/justfile": "build:\n  echo\n",
+			},
+			dir:      "tools/x",
+			want:     "tools",
+			detector: "justfile",
+		},
+		{
+			name: "marked justfile above the repository",
+			files: map[string]string{
+				"justfile":   "# j: root\n",
+				"repo/.git/": "",
+				"repo/sub/":  "",
+			},
+			dir:      "repo/sub",
+			want:     "repo",
+			detector: "git",
+		},
+		{
+			name: "marked justfile without version control",
+			files: map[string]string{
+				"justfile": "# j: root\n\nbuild:\n  echo\n",
+				"a/":       "",
+			},
+			dir:      "a",
+			want:     ".",
+			detector: "justfile",
+		},
+		{
+			name: "annotation below a recipe",
+			files: map[string]string{
+				".git/":        "",
+				"sub/justfile": "build:\n  echo\n\n# j: root\n",
+			},
+			dir:      "sub",
+			want:     ".",
+			detector: "git",
+		},
+		{
+			name: "marker file above the repository",
+			files: map[string]string{
+				".j-root":    "",
+				"repo/.git/": "",
+				"repo/sub/":  "",
+			},
+			dir:      "repo/sub",
+			want:     ".",
+			detector: "marker",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			root := t.TempDir()
+			testrepo.Write(t, root, tt.files)
+
+			got, err := Detect(filepath.Join(root, tt.dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(root, tt.want); got.Path != want || got.Detector != tt.detector {
+				t.Errorf("Detect(%s) = %s (%s), want %s (%s)", tt.dir, got.Path, got.Detector, want, tt.detector)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sleexyz/j/internal/config"
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+// Detector recognizes one kind of repository root
+type Detector struct {
+	// Name identifies the detector in --verbose and j doctor output
+	Name string
+	// Detect returns the root of the repository containing dir, if any
+	Detect func(dir string) (string, bool)
+}
+
+// MarkerDetectors find roots explicitly marked for j. They take precedence
+// over version control so a marker can span several repositories.
+var MarkerDetectors = []Detector{
+	{Name: "marker", Detect: detectMarkerFile},
+	{Name: "justfile", Detect: detectRootJustfile},
+}
+
+// VCSDetectors find version control roots
+var VCSDetectors = []Detector{
+	{Name: "git", Detect: detectGit},
+	{Name: "jj", Detect: dirDetector(".jj")},
+	{Name: "hg", Detect: dirDetector(".hg")},
+	{Name: "sapling", Detect: dirDetector(".sl")},
+}
+
+// findUp returns the first directory from dir upwards for which match is true
+func findUp(dir string, match func(dir string) bool) (string, bool) {
+	for {
+		if match(dir) {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// isDir reports whether path is a directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// dirDetector detects roots by a metadata directory like .hg
+func dirDetector(name string) func(dir string) (string, bool) {
+	return func(dir string) (string, bool) {
+		return findUp(dir, func(dir string) bool {
+			return isDir(filepath.Join(dir, name))
+		})
+	}
+}
+
+// rootMarkers returns the configured root marker file names
+func rootMarkers() []string {
+	if cfg, err := config.Load(""); err == nil && len(cfg.RootMarkers) > 0 {
+		return cfg.RootMarkers
+	}
+	return config.DefaultRootMarkers
+}
+
+// detectMarkerFile finds the nearest directory containing a root marker file
+func detectMarkerFile(dir string) (string, bool) {
+	markers := rootMarkers()
+	return findUp(dir, func(dir string) bool {
+		for _, marker := range markers {
+			if exists(filepath.Join(dir, marker)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// detectRootJustfile finds the nearest justfile marked with a `# j: root`
+// comment at the top. Unlike a marker file it never reaches above the
+// version control root, so a stray ~/justfile can't take over.
+func detectRootJustfile(dir string) (string, bool) {
+	path, ok := findUp(dir, func(dir string) bool {
+		return hasRootAnnotation(filepath.Join(dir, "justfile"))
+	})
+	if !ok {
+		return "", false
+	}
+	if vcs := nearest(dir, VCSDetectors); vcs != nil && !within(path, vcs.Path) {
+		return "", false
+	}
+	return path, true
+}
+
+// hasRootAnnotation scans the comments at the top of the justfile at path,
+// before any recipe, setting or assignment, for a `# j: root` annotation
+func hasRootAnnotation(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			return false
+		}
+		if annotations, ok := justfile.ParseAnnotation(line); ok {
+			if _, ok := annotations["root"]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
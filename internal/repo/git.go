@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// detectGit finds the nearest git work tree by walking up for a .git entry,
+// without spawning git. A .git directory is a regular repository; a .git file
+// holds a `gitdir:` pointer (worktrees and submodules) and only counts if the
+// directory it points to exists.
+func detectGit(dir string) (string, bool) {
+	return findUp(dir, func(dir string) bool {
+		_, ok := gitDir(dir)
+		return ok
+	})
+}
+
+// gitDir returns the git directory for a work tree rooted at dir, following
+// `gitdir:` pointers in .git files
+func gitDir(dir string) (string, bool) {
+	dotGit := filepath.Join(dir, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", false
+	}
+	if info.IsDir() {
+		return dotGit, true
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", false
+	}
+	pointer, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", false
+	}
+
+	target := strings.TrimSpace(pointer)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	if !isDir(target) {
+		return "", false
+	}
+	return filepath.Clean(target), true
+}
+
+// gitCommandRoot asks git for the top level of the work tree containing dir.
+// It's the fallback for setups the native walk can't see, like GIT_DIR.
+func gitCommandRoot(dir string) (string, bool) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(output)), true
+}
+
+// gitSuperproject returns the outermost superproject work tree containing the
+// submodule work tree at dir. ok is false if dir isn't a submodule.
+func gitSuperproject(dir string) (string, bool) {
+	// Submodules have a .git file pointing into the superproject's
+	// .git/modules; plain repositories have a .git directory
+	if isDir(filepath.Join(dir, ".git")) {
+		return "", false
+	}
+	target, ok := gitDir(dir)
+	sep := string(filepath.Separator)
+	if !ok || !strings.Contains(target, sep+"modules"+sep) {
+		return "", false
+	}
+
+	// The common layout can be read straight from the path. Nested submodules
+	// live under the outermost superproject's .git/modules too.
+	if i := strings.Index(target, sep+".git"+sep+"modules"+sep); i >= 0 {
+		if super := target[:i]; isDir(filepath.Join(super, ".git")) {
+			return super, true
+		}
+	}
+
+	// Anything else (e.g. submodules of a worktree): ask git, one level at a time
+	var outer string
+	for current := dir; ; {
+		cmd := exec.Command("git", "rev-parse", "--show-superproject-working-tree")
+		cmd.Dir = current
+		output, err := cmd.Output()
+		super := strings.TrimSpace(string(output))
+		if err != nil || super == "" {
+			break
+		}
+		outer, current = super, super
+	}
+	return outer, outer != ""
+}
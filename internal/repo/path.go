@@ -0,0 +1,201 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sleexyz/j/internal/fuzzy"
+	"github.com/sleexyz/j/internal/justfile"
+)
+
+// Errors returned (wrapped in a *PathError) when an @path can't be used
+var (
+	ErrNotExist     = errors.New("no such directory")
+	ErrOutsideRepo  = errors.New("path is outside the repository")
+	ErrNotDirectory = errors.New("not a directory")
+	ErrAmbiguous    = errors.New("ambiguous path")
+	ErrNoAlias      = errors.New("no such alias")
+)
+
+// maxCandidates limits how many matches an ambiguous path error lists
+const maxCandidates = 10
+
+// PathError records an @path that failed to resolve and why
+type PathError struct {
+	Path string
+	Root string
+	Err  error
+	// Candidates lists the matching @paths, best first, for ErrAmbiguous
+	Candidates []string
+}
+
+func (e *PathError) Error() string {
+	msg := fmt.Sprintf("%s: %v (repository root %s)", e.Path, e.Err, e.Root)
+	if len(e.Candidates) > 0 {
+		msg += "; did you mean one of:"
+		for _, candidate := range e.Candidates {
+			msg += "\n  " + candidate
+		}
+	}
+	return msg
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// Resolution is an @path resolved to a directory
+type Resolution struct {
+	// Path is the absolute directory
+	Path string
+	// RepoPath is the canonical @path for the directory
+	RepoPath string
+	// Abbreviated is set when RepoPath was matched from an abbreviation
+	// rather than spelled out in full
+	Abbreviated bool
+	// Alias is the alias name the @path started with, if any
+	Alias string
+}
+
+// ResolveRepoPath resolves @path/to/dir to actual filesystem path
+func ResolveRepoPath(repoPath, repoRoot string) (string, error) {
+	resolution, err := LookupRepoPath(repoPath, repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return resolution.Path, nil
+}
+
+// LookupRepoPath resolves @path/to/dir against repoRoot. The result must be
+// an existing directory inside repoRoot, both before and after following
+// symlinks. When no such directory exists, a leading alias is expanded;
+// failing that, the path is matched against the directories containing
+// justfiles: by trailing path components (@api for services/api), then by
+// prefix, then fuzzily. Only a unique match is used.
+func LookupRepoPath(repoPath, repoRoot string) (*Resolution, error) {
+	return lookupRepoPath(repoPath, repoRoot, true)
+}
+
+// lookupRepoPath is LookupRepoPath; aliases and abbreviations are only
+// tried when expand is set
+func lookupRepoPath(repoPath, repoRoot string, expand bool) (*Resolution, error) {
+	// Remove @ prefix
+	relPath := strings.TrimPrefix(repoPath, "@")
+	fail := func(err error) (*Resolution, error) {
+		return nil, &PathError{Path: "@" + relPath, Root: repoRoot, Err: err}
+	}
+
+	fullPath := filepath.Join(repoRoot, relPath)
+	if !within(fullPath, repoRoot) {
+		return fail(ErrOutsideRepo)
+	}
+
+	info, err := os.Stat(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if !expand {
+			return fail(ErrNotExist)
+		}
+		if expanded, alias := expandAlias(relPath, repoRoot); alias != nil {
+			return lookupExpanded(relPath, expanded, alias, repoRoot)
+		}
+		return matchRepoPath(relPath, repoRoot)
+	} else if err != nil {
+		return fail(err)
+	}
+	if !info.IsDir() {
+		return fail(ErrNotDirectory)
+	}
+
+	// A symlink inside the repo may still point somewhere else entirely
+	realRoot, err := filepath.EvalSymlinks(repoRoot)
+	if err != nil {
+		return fail(err)
+	}
+	realPath, err := filepath.EvalSymlinks(fullPath)
+	if err != nil {
+		return fail(err)
+	}
+	if !within(realPath, realRoot) {
+		return fail(ErrOutsideRepo)
+	}
+
+	repoPath = "@" + filepath.ToSlash(filepath.Clean(relPath))
+	if repoPath == "@." {
+		repoPath = "@"
+	}
+	return &Resolution{Path: fullPath, RepoPath: repoPath}, nil
+}
+
+// matchRepoPath finds the unique justfile directory abbreviated by relPath
+func matchRepoPath(relPath, repoRoot string) (*Resolution, error) {
+	input := strings.Trim(filepath.ToSlash(relPath), "/")
+	fail := func(err error, candidates []string) (*Resolution, error) {
+		return nil, &PathError{Path: "@" + relPath, Root: repoRoot, Err: err, Candidates: candidates}
+	}
+	if input == "" {
+		return fail(ErrNotExist, nil)
+	}
+
+	justfiles, err := justfile.FindAllJustfiles(repoRoot)
+	if err != nil {
+		return fail(err, nil)
+	}
+
+	var dirs []string
+	for _, justfilePath := range justfiles {
+		rel, err := filepath.Rel(repoRoot, filepath.Dir(justfilePath))
+		if err != nil || rel == "." {
+			continue
+		}
+		dirs = append(dirs, filepath.ToSlash(rel))
+	}
+
+	// Try each tier in turn; the first with any matches decides
+	tiers := []func(dir string) bool{
+		func(dir string) bool {
+			return strings.HasSuffix("/"+dir, "/"+input)
+		},
+		func(dir string) bool {
+			return strings.HasPrefix(dir, input) || strings.HasPrefix(filepath.Base(dir), input)
+		},
+		func(dir string) bool {
+			return fuzzy.Score(input, dir) > 0
+		},
+	}
+	for _, matches := range tiers {
+		var found []string
+		for _, dir := range dirs {
+			if matches(dir) {
+				found = append(found, dir)
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return &Resolution{
+				Path:        filepath.Join(repoRoot, filepath.FromSlash(found[0])),
+				RepoPath:    "@" + found[0],
+				Abbreviated: true,
+			}, nil
+		}
+
+		sort.SliceStable(found, func(i, j int) bool {
+			return fuzzy.Score(input, found[i]) > fuzzy.Score(input, found[j])
+		})
+		if len(found) > maxCandidates {
+			found = found[:maxCandidates]
+		}
+		for i := range found {
+			found[i] = "@" + found[i]
+		}
+		return fail(ErrAmbiguous, found)
+	}
+
+	return fail(ErrNotExist, nil)
+}
@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sleexyz/j/internal/testrepo"
+)
+
+// setupPaths creates a repository for @path resolution, with symlinks both
+// inside it and out of it
+func setupPaths(t *testing.T) string {
+	t.Helper()
+	isolate(t)
+	root := t.TempDir()
+	testrepo.Write(t, root, map[string]string{
+		"justfile":              "build:\n  echo\n",
+		"services/api/justfile": "build:\n  echo\n",
+		"services/web/justfile": "build:\n  echo\n",
+		"apps/web/justfile":     "build:\n  echo\n",
+		"notes.txt":             "",
+	})
+	for link, target := range map[string]string{
+		"etc":              "/etc",
+		"inner":            "services",
+		"services/api/up":  "../../..",
+		"services/api/out": root + "/..",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLookupRepoPath(t *testing.T) {
+	root := setupPaths(t)
+
+	tests := []struct {
+		repoPath    string
+		want        string
+		wantPath    string
+		abbreviated bool
+		err         error
+	}{
+		{repoPath: "@", want: "@", wantPath: "."},
+		{repoPath: "@services/api", want: "@services/api", wantPath: "services/api"},
+		{repoPath: "@services/api/", want: "@services/api", wantPath: "services/api"},
+		{repoPath: "@services/../apps/web", want: "@apps/web", wantPath: "apps/web"},
+		{repoPath: "@inner/api", want: "@inner/api", wantPath: "inner/api"},
+		{repoPath: "@api", want: "@services/api", wantPath: "services/api", abbreviated: true},
+		{repoPath: "@..", err: ErrOutsideRepo},
+		{repoPath: "@../..", err: ErrOutsideRepo},
+		{repoPath: "@services/../../x", err: ErrOutsideRepo},
+		{repoPath: "@etc", err: ErrOutsideRepo},
+		{repoPath: "@services/api/up", err: ErrOutsideRepo},
+		{repoPath: "@services/api/out", err: ErrOutsideRepo},
+		{repoPath: "@notes.txt", err: ErrNotDirectory},
+		{repoPath: "@missing", err: ErrNotExist},
+		{repoPath: "@web", err: ErrAmbiguous},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repoPath, func(t *testing.T) {
+			got, err := LookupRepoPath(tt.repoPath, root)
+			if tt.err != nil {
+				var pathErr *PathError
+				if !errors.Is(err, tt.err) || !errors.As(err, &pathErr) {
+					t.Fatalf("LookupRepoPath(%s) = %+v, %v, want a *PathError for %v", tt.repoPath, got, err, tt.err)
+				}
+				if tt.err == ErrAmbiguous && len(pathErr.Candidates) != 2 {
+					t.Errorf("LookupRepoPath(%s) candidates = %v, want @apps/web and @services/web", tt.repoPath, pathErr.Candidates)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupRepoPath(%s): %v", tt.repoPath, err)
+			}
+			if got.RepoPath != tt.want || got.Path != filepath.Join(root, tt.wantPath) || got.Abbreviated != tt.abbreviated {
+				t.Errorf("LookupRepoPath(%s) = %+v, want %s at %s (abbreviated %v)", tt.repoPath, got, tt.want, tt.wantPath, tt.abbreviated)
+			}
+		})
+	}
+}
+
+func TestLookupRelative(t *testing.T) {
+	root := setupPaths(t)
+	r := &Root{Path: root, Inner: root}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+
+	tests := []struct {
+		cwd      string
+		repoPath string
+		want     string
+		err      error
+	}{
+		{cwd: "services", repoPath: "@./api", want: "@services/api"},
+		{cwd: "services/api", repoPath: "@..", want: "@services"},
+		{cwd: "services/api", repoPath: "@../../apps/web", want: "@apps/web"},
+		{cwd: ".", repoPath: "@.", want: "@"},
+		{cwd: ".", repoPath: "@..", err: ErrOutsideRepo},
+		{cwd: "services", repoPath: "@../..", err: ErrOutsideRepo},
+		// Never expanded as an abbreviation
+		{cwd: ".", repoPath: "@./api", err: ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cwd+" "+tt.repoPath, func(t *testing.T) {
+			if err := os.Chdir(filepath.Join(root, tt.cwd)); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Lookup(tt.repoPath)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Lookup(%s) from %s = %+v, %v, want %v", tt.repoPath, tt.cwd, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup(%s) from %s: %v", tt.repoPath, tt.cwd, err)
+			}
+			if got.RepoPath != tt.want {
+				t.Errorf("Lookup(%s) from %s = %s, want %s", tt.repoPath, tt.cwd, got.RepoPath, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupAlias(t *testing.T) {
+	isolate(t)
+	root := t.TempDir()
+	testrepo.Write(t, root, map[string]string{
+		".j.json":               `{"aliases": {"be": "services/api"}}`,
+		"services/api/justfile": "# API\n# j: name=api\n\nbuild:\n  echo\n",
+		"apps/web/justfile":     "build:\n  echo\n\n# j: name=mid\n\ntest:\n  echo\n",
+	})
+
+	tests := []struct {
+		alias string
+		want  string
+		err   error
+	}{
+		{alias: "be", want: "@services/api"},
+		{alias: "api", want: "@services/api"},
+		{alias: "mid", err: ErrNoAlias},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			got, err := LookupAlias(tt.alias, root)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("LookupAlias(%s) = %+v, %v, want %v", tt.alias, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupAlias(%s): %v", tt.alias, err)
+			}
+			if got.RepoPath != tt.want || got.Alias != tt.alias {
+				t.Errorf("LookupAlias(%s) = %+v, want %s through alias %s", tt.alias, got, tt.want, tt.alias)
+			}
+		})
+	}
+}
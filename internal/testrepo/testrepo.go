@@ -0,0 +1,57 @@
+// Package testrepo builds throwaway repositories for tests
+package testrepo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// New creates a repository in a temporary directory with files (see Write)
+// and points j at it through J_ROOT. j's user config and history are moved
+// into it too, so the user's own don't affect the test.
+func New(t testing.TB, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	Write(t, root, files)
+
+	t.Setenv("J_ROOT", root)
+	t.Setenv("J_CONFIG", filepath.Join(root, "no-config.json"))
+	t.Setenv("J_HISTORY", filepath.Join(root, "no-history.jsonl"))
+	return root
+}
+
+// Write creates files, keyed by their paths relative to dir, with their
+// directories. A path ending in / is created as an empty directory.
+func Write(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Chdir changes the current directory to dir until the test ends
+func Chdir(t testing.TB, dir string) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ANSI SGR codes used by j's colored output
+const (
+	Bold    = "1"
+	Dim     = "2"
+	Red     = "31"
+	Green   = "32"
+	Yellow  = "33"
+	Blue    = "34"
+	Magenta = "35"
+	Cyan    = "36"
+)
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ColorEnabled decides whether to color output written to f. mode is one of
+// "auto" (color terminals unless NO_COLOR is set), "always" or "never".
+func ColorEnabled(f *os.File, mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		return IsTerminal(f) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb", nil
+	default:
+		return false, fmt.Errorf("invalid color mode %q (expected auto, always or never)", mode)
+	}
+}
+
+// Paint wraps s in the given SGR codes
+func Paint(s string, codes ...string) string {
+	if s == "" || len(codes) == 0 {
+		return s
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + s + "\x1b[0m"
+}